@@ -0,0 +1,305 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tablePrefixPattern matches the prefixes that are safe to splice into SQL identifiers
+var tablePrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidTablePrefix reports whether prefix can be spliced into table and index names
+// The repository checks it too, for configurations that weren't validated
+func ValidTablePrefix(prefix string) bool {
+	return prefix == "" || tablePrefixPattern.MatchString(prefix)
+}
+
+// logLevels are the accepted log_level values
+var logLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// basePathPattern matches URL paths like /events-api made of plain segments, without a trailing slash
+var basePathPattern = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)*$`)
+
+// cacheRoutePattern matches Cache-Control routes like GET /api/v1/events/:id
+var cacheRoutePattern = regexp.MustCompile(`^[A-Z]+ /\S*$`)
+
+// Duration is a time.Duration read from JSON as a string like "90s" or "365d"
+type Duration time.Duration
+
+// UnmarshalJSON parses the duration with ParseDuration
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"1h\": %w", err)
+	}
+
+	parsed, err := ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a Go duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// ParseDuration parses a Go duration, or a whole number of days like 365d
+func ParseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Config holds the application settings
+// JSON names match the environment variables, lower cased
+type Config struct {
+	// LogLevel is the lowest level logged: debug, info, warn or error
+	LogLevel string `json:"log_level"`
+	// Port is the HTTP port the server listens on
+	Port string `json:"port"`
+	// GRPCPort is the port of the gRPC API, empty disables it
+	GRPCPort string `json:"grpc_port"`
+	// BasePath is the path every route is mounted under, such as /events-api behind a reverse proxy
+	BasePath string `json:"base_path"`
+	// DBPath is the SQLite database file, or :memory:
+	DBPath string `json:"db_path"`
+	// TablePrefix is prepended to every table and index name
+	TablePrefix string `json:"table_prefix"`
+	// UUIDVersion is the version of generated event IDs, 4 for random or 7 for time-ordered
+	UUIDVersion int `json:"uuid_version"`
+	// DBConnectRetries is how many times connecting to the database is retried on startup
+	DBConnectRetries int `json:"db_connect_retries"`
+	// DBConnectInterval is the delay before the first retry, doubled after each one
+	DBConnectInterval Duration `json:"db_connect_interval"`
+	// BusyRetries is how many times writes are retried when the database is busy
+	BusyRetries int `json:"db_busy_retries"`
+	// SlowQueryMS is how many milliseconds a query may take before it is logged, zero disables it
+	SlowQueryMS int `json:"slow_query_ms"`
+	// DBMaxOpenConns and DBMaxIdleConns size the pool of read connections, writes always
+	// go through a single connection of their own
+	DBMaxOpenConns int `json:"db_max_open_conns"`
+	DBMaxIdleConns int `json:"db_max_idle_conns"`
+
+	// ReadTimeout and WriteTimeout bound reading a request and writing its response,
+	// zero means no limit. The event stream, exports and profiles aren't bound by WriteTimeout.
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"`
+	// ShutdownTimeout is how long in-flight requests get to finish on shutdown
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	// TLSCert and TLSKey are PEM files; when both are set the server serves HTTPS instead of HTTP
+	TLSCert string `json:"tls_cert"`
+	TLSKey  string `json:"tls_key"`
+
+	// APIKeys are key:user or key:user:admin entries, none disables authentication
+	APIKeys []string `json:"api_keys"`
+	// CORSAllowOrigins are the origins browsers may call the API from
+	CORSAllowOrigins []string `json:"cors_allow_origins"`
+
+	// DefaultDuration gives end_time to requests omitting it, zero keeps end_time required
+	DefaultDuration Duration `json:"default_duration"`
+	// WindowLimit is the maximum number of events overlapping the window around a new event
+	WindowLimit int `json:"event_window_limit"`
+	// Window is how far around a new event existing events are counted
+	Window Duration `json:"event_window"`
+	// AllowZeroDuration accepts events whose end_time equals their start_time
+	AllowZeroDuration bool `json:"allow_zero_duration"`
+	// StrictTimeParsing only accepts RFC 3339 timestamps with an explicit offset
+	StrictTimeParsing bool `json:"strict_time_parsing"`
+	// MinEventYear and MaxEventYear bound the years start_time and end_time may fall in
+	MinEventYear int `json:"min_event_year"`
+	MaxEventYear int `json:"max_event_year"`
+	// MaxEventsPerOwner caps the active events a non-admin user may have, zero disables it
+	MaxEventsPerOwner int `json:"max_events_per_owner"`
+	// DedupWindow is how long identical create requests get the first one's response,
+	// zero disables deduplication
+	DedupWindow Duration `json:"dedup_window"`
+
+	// CountReconcileInterval is how often the cached event count is checked against the database
+	CountReconcileInterval Duration `json:"count_reconcile_interval"`
+	// EventRetention is how long after their end events are kept, zero keeps them forever
+	EventRetention Duration `json:"event_retention"`
+	// CleanupInterval is how often events past the retention period are removed
+	CleanupInterval Duration `json:"cleanup_interval"`
+	// EnablePprof mounts the profiling handlers under /debug/pprof
+	EnablePprof bool `json:"enable_pprof"`
+	// QueryBudget is how many database operations a request may run before it is logged,
+	// zero disables the check
+	QueryBudget int `json:"query_budget"`
+	// DevMode fails requests over the query budget and reports their query count
+	DevMode bool `json:"dev_mode"`
+	// GzipLevel is the gzip compression level of responses, from 1 to 9, zero disables compression
+	GzipLevel int `json:"gzip_level"`
+	// GzipMinLength is the smallest response body compressed, in bytes
+	GzipMinLength int `json:"gzip_min_length"`
+	// MaxExpensiveRequests caps the exports and batch requests running at once, zero for no limit
+	MaxExpensiveRequests int `json:"max_expensive_requests"`
+	// MaxRangeSpan is the longest time range recurring events are expanded over, zero for no limit
+	MaxRangeSpan Duration `json:"max_range_span"`
+	// CacheControl maps routes, as "GET /api/v1/events/:id", to the Cache-Control directive
+	// of their successful responses; other responses get no-store
+	CacheControl map[string]string `json:"cache_control"`
+	// ReadOnly rejects writes with 503 during maintenance while reads keep working
+	ReadOnly bool `json:"read_only"`
+	// BackupPath is the file POST /maintenance/backup writes to, empty disables backups
+	BackupPath string `json:"backup_path"`
+}
+
+// Default returns the settings used when neither the config file nor the environment sets them
+func Default() Config {
+	return Config{
+		LogLevel:               "info",
+		Port:                   "8080",
+		DBPath:                 "./events.db",
+		UUIDVersion:            4,
+		DBConnectRetries:       5,
+		DBConnectInterval:      Duration(time.Second),
+		BusyRetries:            5,
+		SlowQueryMS:            200,
+		DBMaxOpenConns:         4,
+		DBMaxIdleConns:         4,
+		ShutdownTimeout:        Duration(10 * time.Second),
+		CORSAllowOrigins:       []string{"*"},
+		AllowZeroDuration:      true,
+		MinEventYear:           1970,
+		MaxEventYear:           2100,
+		CountReconcileInterval: Duration(time.Minute),
+		CleanupInterval:        Duration(time.Hour),
+		MaxExpensiveRequests:   2,
+		MaxRangeSpan:           Duration(366 * 24 * time.Hour),
+		GzipLevel:              6,
+		GzipMinLength:          1024,
+	}
+}
+
+// Load builds the configuration from the defaults, the JSON file named by CONFIG_FILE
+// when set, and the environment variables, each overriding the previous one
+// The result is validated; every invalid setting is reported in the error
+func Load() (Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	// Settings that fail to parse keep their previous value, so validation still applies
+	if err := errors.Join(cfg.loadEnv(), cfg.Validate()); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that every setting is usable
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(logLevels[strings.ToLower(cfg.LogLevel)], "log_level must be debug, info, warn or error, got %q", cfg.LogLevel)
+
+	port, err := strconv.Atoi(cfg.Port)
+	check(err == nil && port > 0 && port <= 65535, "port must be a number between 1 and 65535, got %q", cfg.Port)
+	if cfg.GRPCPort != "" {
+		grpcPort, err := strconv.Atoi(cfg.GRPCPort)
+		check(err == nil && grpcPort > 0 && grpcPort <= 65535, "grpc_port must be a number between 1 and 65535, got %q", cfg.GRPCPort)
+		check(cfg.GRPCPort != cfg.Port, "grpc_port must differ from port")
+	}
+	check(basePathPattern.MatchString(cfg.BasePath),
+		"base_path %q must start with / and not end with one, like /events-api", cfg.BasePath)
+	check(cfg.DBPath != "", "db_path is required")
+	check(cfg.BackupPath == "" || filepath.Clean(cfg.BackupPath) != filepath.Clean(cfg.DBPath),
+		"backup_path must not be the database file")
+	check(ValidTablePrefix(cfg.TablePrefix),
+		"table_prefix %q may only contain letters, digits and _, and not start with a digit", cfg.TablePrefix)
+	check(cfg.UUIDVersion == 4 || cfg.UUIDVersion == 7, "uuid_version must be 4 or 7, got %d", cfg.UUIDVersion)
+	check(cfg.DBConnectRetries >= 0, "db_connect_retries must not be negative")
+	check(cfg.DBConnectInterval > 0, "db_connect_interval must be positive")
+	check(cfg.BusyRetries >= 0, "db_busy_retries must not be negative")
+	check(cfg.SlowQueryMS >= 0, "slow_query_ms must not be negative")
+	check(cfg.DBMaxOpenConns > 0, "db_max_open_conns must be positive")
+	check(cfg.DBMaxIdleConns >= 0 && cfg.DBMaxIdleConns <= cfg.DBMaxOpenConns,
+		"db_max_idle_conns must be between 0 and db_max_open_conns")
+
+	check(cfg.ReadTimeout >= 0, "read_timeout must not be negative")
+	check(cfg.WriteTimeout >= 0, "write_timeout must not be negative")
+	check(cfg.ShutdownTimeout > 0, "shutdown_timeout must be positive")
+	check((cfg.TLSCert == "") == (cfg.TLSKey == ""), "tls_cert and tls_key must be set together")
+
+	for _, origin := range cfg.CORSAllowOrigins {
+		check(origin != "", "cors_allow_origins must not contain empty origins")
+	}
+
+	check(cfg.DefaultDuration >= 0, "default_duration must not be negative")
+	check(cfg.WindowLimit >= 0, "event_window_limit must not be negative")
+	check(cfg.Window >= 0, "event_window must not be negative")
+	check(cfg.MinEventYear >= 1 && cfg.MaxEventYear <= 9999 && cfg.MinEventYear <= cfg.MaxEventYear,
+		"min_event_year and max_event_year must be between 1 and 9999, min_event_year first")
+	check(cfg.MaxEventsPerOwner >= 0, "max_events_per_owner must not be negative")
+	check(cfg.DedupWindow >= 0, "dedup_window must not be negative")
+
+	check(cfg.CountReconcileInterval > 0, "count_reconcile_interval must be positive")
+	check(cfg.EventRetention >= 0, "event_retention must not be negative")
+	check(cfg.CleanupInterval > 0, "cleanup_interval must be positive")
+	check(cfg.QueryBudget >= 0, "query_budget must not be negative")
+	check(cfg.MaxExpensiveRequests >= 0, "max_expensive_requests must not be negative")
+	check(cfg.MaxRangeSpan >= 0, "max_range_span must not be negative")
+	check(cfg.GzipLevel >= 0 && cfg.GzipLevel <= 9, "gzip_level must be between 0 and 9, got %d", cfg.GzipLevel)
+	check(cfg.GzipMinLength >= 0, "gzip_min_length must not be negative")
+	for route, directive := range cfg.CacheControl {
+		check(cacheRoutePattern.MatchString(route),
+			"cache_control route %q must be a method and a path, like GET /api/v1/events/:id", route)
+		check(strings.TrimSpace(directive) != "", "cache_control directive of %q must not be empty", route)
+	}
+
+	return errors.Join(errs...)
+}
+
+// SlogLevel returns LogLevel as a slog level, info when it is invalid
+func (cfg *Config) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (cfg *Config) TLSEnabled() bool {
+	return cfg.TLSCert != "" && cfg.TLSKey != ""
+}
+
+// loadFile overrides the settings present in the JSON file at path
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate(Default()) = %v", err)
+	}
+
+	if cfg.Port != "8080" || cfg.DBPath != "./events.db" || cfg.LogLevel != "info" {
+		t.Errorf("port, db_path, log_level = %q, %q, %q", cfg.Port, cfg.DBPath, cfg.LogLevel)
+	}
+	if cfg.WindowLimit != 0 || cfg.DedupWindow != 0 || len(cfg.APIKeys) != 0 {
+		t.Errorf("window limit, dedup and authentication should be disabled by default")
+	}
+	if !cfg.AllowZeroDuration || cfg.MinEventYear != 1970 || cfg.MaxEventYear != 2100 {
+		t.Errorf("allow_zero_duration, min_event_year, max_event_year = %v, %d, %d",
+			cfg.AllowZeroDuration, cfg.MinEventYear, cfg.MaxEventYear)
+	}
+	if time.Duration(cfg.ShutdownTimeout) != 10*time.Second || cfg.DBMaxOpenConns != 4 {
+		t.Errorf("shutdown_timeout, db_max_open_conns = %s, %d", time.Duration(cfg.ShutdownTimeout), cfg.DBMaxOpenConns)
+	}
+}
+
+func TestValidateFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   string
+	}{
+		{"non-numeric port", func(c *Config) { c.Port = "http" }, "port must be a number"},
+		{"port out of range", func(c *Config) { c.Port = "70000" }, "port must be a number"},
+		{"same gRPC port", func(c *Config) { c.GRPCPort = c.Port }, "grpc_port must differ"},
+		{"unknown log level", func(c *Config) { c.LogLevel = "verbose" }, "log_level"},
+		{"trailing slash in base path", func(c *Config) { c.BasePath = "/api/" }, "base_path"},
+		{"empty database path", func(c *Config) { c.DBPath = "" }, "db_path is required"},
+		{"unsafe table prefix", func(c *Config) { c.TablePrefix = "1; DROP" }, "table_prefix"},
+		{"backup over the database", func(c *Config) { c.BackupPath = c.DBPath }, "backup_path"},
+		{"negative window limit", func(c *Config) { c.WindowLimit = -1 }, "event_window_limit"},
+		{"idle above open connections", func(c *Config) { c.DBMaxIdleConns = c.DBMaxOpenConns + 1 }, "db_max_idle_conns"},
+		{"TLS certificate without key", func(c *Config) { c.TLSCert = "cert.pem" }, "tls_cert and tls_key"},
+		{"inverted year range", func(c *Config) { c.MinEventYear, c.MaxEventYear = 2100, 1970 }, "min_event_year"},
+		{"gzip level above 9", func(c *Config) { c.GzipLevel = 10 }, "gzip_level"},
+		{"cache route without method", func(c *Config) { c.CacheControl = map[string]string{"/api/v1/events": "no-cache"} }, "cache_control route"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.change(&cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryFailure(t *testing.T) {
+	cfg := Default()
+	cfg.Port = "http"
+	cfg.WindowLimit = -1
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "port") || !strings.Contains(err.Error(), "event_window_limit") {
+		t.Errorf("Validate = %v, want both the port and event_window_limit errors", err)
+	}
+}
+
+func TestLoadOverridesFileWithEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	file := `{"port": "9090", "event_window": "30m", "event_retention": "365d", "api_keys": ["k:alice:admin"]}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "7070")
+	t.Setenv("CACHE_CONTROL", "GET /api/v1/events/:id=private, max-age=30;GET /api/v1/events=no-cache")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Port != "7070" {
+		t.Errorf("port = %q, want the environment's 7070", cfg.Port)
+	}
+	if time.Duration(cfg.Window) != 30*time.Minute || time.Duration(cfg.EventRetention) != 365*24*time.Hour {
+		t.Errorf("event_window, event_retention = %s, %s", time.Duration(cfg.Window), time.Duration(cfg.EventRetention))
+	}
+	if len(cfg.APIKeys) != 1 || cfg.APIKeys[0] != "k:alice:admin" {
+		t.Errorf("api_keys = %q", cfg.APIKeys)
+	}
+	if got := cfg.CacheControl["GET /api/v1/events/:id"]; got != "private, max-age=30" {
+		t.Errorf("cache_control of GET /api/v1/events/:id = %q", got)
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	tests := map[string]string{
+		"EVENT_WINDOW_LIMIT": "many",
+		"EVENT_WINDOW":       "soon",
+		"DEV_MODE":           "maybe",
+		"PORT":               "http",
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			t.Setenv(name, value)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load with %s=%s succeeded, want an error", name, value)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"prot": "9090"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("Load = %v, want an unknown field error", err)
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// loadEnv overrides the settings whose environment variable is set
+// Values that don't parse are all reported in the returned error
+func (cfg *Config) loadEnv() error {
+	errs := []error{
+		envString("LOG_LEVEL", &cfg.LogLevel),
+		envString("PORT", &cfg.Port),
+		envString("GRPC_PORT", &cfg.GRPCPort),
+		envString("BASE_PATH", &cfg.BasePath),
+		envString("DB_PATH", &cfg.DBPath),
+		envString("TABLE_PREFIX", &cfg.TablePrefix),
+		envInt("UUID_VERSION", &cfg.UUIDVersion),
+		envInt("DB_CONNECT_RETRIES", &cfg.DBConnectRetries),
+		envDuration("DB_CONNECT_INTERVAL", &cfg.DBConnectInterval),
+		envInt("DB_BUSY_RETRIES", &cfg.BusyRetries),
+		envInt("SLOW_QUERY_MS", &cfg.SlowQueryMS),
+		envInt("DB_MAX_OPEN_CONNS", &cfg.DBMaxOpenConns),
+		envInt("DB_MAX_IDLE_CONNS", &cfg.DBMaxIdleConns),
+
+		envDuration("READ_TIMEOUT", &cfg.ReadTimeout),
+		envDuration("WRITE_TIMEOUT", &cfg.WriteTimeout),
+		envDuration("SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout),
+		envString("TLS_CERT", &cfg.TLSCert),
+		envString("TLS_KEY", &cfg.TLSKey),
+
+		envList("API_KEYS", &cfg.APIKeys),
+		envList("CORS_ALLOW_ORIGINS", &cfg.CORSAllowOrigins),
+
+		envDuration("DEFAULT_DURATION", &cfg.DefaultDuration),
+		envInt("EVENT_WINDOW_LIMIT", &cfg.WindowLimit),
+		envDuration("EVENT_WINDOW", &cfg.Window),
+		envBool("ALLOW_ZERO_DURATION", &cfg.AllowZeroDuration),
+		envBool("STRICT_TIME_PARSING", &cfg.StrictTimeParsing),
+		envInt("MIN_EVENT_YEAR", &cfg.MinEventYear),
+		envInt("MAX_EVENT_YEAR", &cfg.MaxEventYear),
+		envInt("MAX_EVENTS_PER_OWNER", &cfg.MaxEventsPerOwner),
+		envDuration("DEDUP_WINDOW", &cfg.DedupWindow),
+
+		envDuration("COUNT_RECONCILE_INTERVAL", &cfg.CountReconcileInterval),
+		envDuration("EVENT_RETENTION", &cfg.EventRetention),
+		envDuration("CLEANUP_INTERVAL", &cfg.CleanupInterval),
+		envBool("ENABLE_PPROF", &cfg.EnablePprof),
+		envInt("QUERY_BUDGET", &cfg.QueryBudget),
+		envBool("DEV_MODE", &cfg.DevMode),
+		envInt("MAX_EXPENSIVE_REQUESTS", &cfg.MaxExpensiveRequests),
+		envDuration("MAX_RANGE_SPAN", &cfg.MaxRangeSpan),
+		envInt("GZIP_LEVEL", &cfg.GzipLevel),
+		envInt("GZIP_MIN_LENGTH", &cfg.GzipMinLength),
+		envRoutes("CACHE_CONTROL", &cfg.CacheControl),
+		envBool("READ_ONLY", &cfg.ReadOnly),
+		envString("BACKUP_PATH", &cfg.BackupPath),
+	}
+	return errors.Join(errs...)
+}
+
+// envString reads a string setting
+func envString(name string, target *string) error {
+	if value := os.Getenv(name); value != "" {
+		*target = value
+	}
+	return nil
+}
+
+// envList reads a comma separated list, dropping empty entries
+func envList(name string, target *[]string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	*target = list
+	return nil
+}
+
+// envRoutes reads route settings given as ROUTE=VALUE entries separated by ;, since values
+// like Cache-Control directives contain commas
+func envRoutes(name string, target *map[string]string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	routes := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		route, setting, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid %s entry %q, expected ROUTE=VALUE", name, entry)
+		}
+		routes[strings.TrimSpace(route)] = strings.TrimSpace(setting)
+	}
+	*target = routes
+	return nil
+}
+
+// envInt reads an integer setting
+func envInt(name string, target *int) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q, expected an integer", name, value)
+	}
+	*target = n
+	return nil
+}
+
+// envDuration reads a duration setting
+func envDuration(name string, target *Duration) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	d, err := ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q, expected a duration like 1h", name, value)
+	}
+	*target = Duration(d)
+	return nil
+}
+
+// envBool reads a boolean setting
+func envBool(name string, target *bool) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q, expected true or false", name, value)
+	}
+	*target = b
+	return nil
+}
@@ -1,44 +1,73 @@
 package main
 
 import (
+	"challenge/config"
 	"challenge/repository"
 	"challenge/service"
+	"challenge/telemetry"
 	"context"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
+// fatal logs the error and exits
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 func main() {
-	ctx := context.Background()
+	// Cancelled on SIGINT/SIGTERM to shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Get database path from environment variable
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "./events.db"
+	// Load configuration from CONFIG_FILE and environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		fatal(slog.Default(), "Failed to load configuration", err)
 	}
 
-	// Create database connection
-	db, err := repository.NewDatabase(ctx, dbPath)
+	// Log at the configured level; the standard log package, used by libraries, logs through it too
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.SlogLevel()}))
+	slog.SetDefault(logger)
+
+	// Set up tracing before anything records spans
+	shutdownTracing, err := telemetry.Setup(ctx, logger)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal(logger, "Failed to set up tracing", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	// Create database connection, waiting for it to become available
+	db, err := repository.Connect(ctx, cfg, logger)
+	if err != nil {
+		fatal(logger, "Failed to connect to database", err)
 	}
 	defer db.Close()
 
 	// Create table if it doesn't exist
 	if err := db.CreateTable(ctx); err != nil {
-		log.Fatalf("Failed to create table: %v", err)
+		fatal(logger, "Failed to create table", err)
 	}
 
 	// Create and start server
-	server := service.NewServer(db)
+	server := service.NewServer(db, cfg, logger)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Serve HTTPS when a certificate is configured, plain HTTP otherwise
+	if cfg.TLSEnabled() {
+		logger.Info("Server starting with TLS", "port", cfg.Port)
+		err = server.StartTLS(ctx, cfg.Port, cfg.TLSCert, cfg.TLSKey)
+	} else {
+		logger.Info("Server starting", "port", cfg.Port)
+		err = server.Start(ctx, cfg.Port)
 	}
-
-	log.Printf("Server starting on port %s", port)
-	if err := server.Start(port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if err != nil {
+		fatal(logger, "Failed to start server", err)
 	}
 }
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// DateFormat is the layout of calendar dates such as 2025-03-01
+const DateFormat = "2006-01-02"
+
+// CloneRequest represents the JSON payload for copying an event to another date
+type CloneRequest struct {
+	TargetDate string `json:"target_date" validate:"required"` // YYYY-MM-DD
+	// TimeZone is the IANA time zone the time of day is kept in, defaulting to the time zone
+	// of the recurrence, or to the event's offset for one-off events
+	TimeZone string `json:"timezone"`
+}
+
+var (
+	InvalidTargetDate    = ValidationError{"INVALID_TARGET_DATE", "target_date must be a date such as 2025-03-01"}
+	InvalidCloneTimeZone = ValidationError{"INVALID_CLONE_TIMEZONE", "timezone must be an IANA time zone name"}
+)
+
+// Validate checks the target date and the time zone, which no configurable rule affects
+func (req *CloneRequest) Validate(Rules) error {
+	if _, err := time.Parse(DateFormat, req.TargetDate); err != nil {
+		return &InvalidTargetDate
+	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			return &InvalidCloneTimeZone
+		}
+	}
+	return nil
+}
+
+// Target returns the target date and the time zone to clone event in for a request that
+// passed validation
+func (req *CloneRequest) Target(event *Event) (time.Time, *time.Location) {
+	date, _ := time.Parse(DateFormat, req.TargetDate)
+
+	loc := event.StartTime.Location()
+	if req.TimeZone != "" {
+		loc, _ = time.LoadLocation(req.TimeZone)
+	} else if event.Recurrence != nil {
+		if recurrenceLoc, err := time.LoadLocation(event.Recurrence.TimeZone); err == nil {
+			loc = recurrenceLoc
+		}
+	}
+	return date, loc
+}
+
+// CloneTo returns a new, unsaved copy of e starting on date at the same wall-clock time in
+// loc. The duration is kept as elapsed time, so a clone landing across a DST transition
+// lasts as long as the original. The recurrence moves with the start like Recurrence.MoveTo,
+// as when the event is rescheduled.
+func (e *Event) CloneTo(date time.Time, loc *time.Location) *Event {
+	start := e.StartTime.In(loc)
+	cloneStart := time.Date(date.Year(), date.Month(), date.Day(),
+		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), loc)
+
+	clone := &Event{
+		Title:      e.Title,
+		StartTime:  cloneStart,
+		EndTime:    cloneStart.Add(e.EndTime.Sub(e.StartTime)),
+		Status:     e.Status,
+		Metadata:   e.Metadata,
+		Links:      slices.Clone(e.Links),
+		Priority:   e.Priority,
+		Tags:       slices.Clone(e.Tags),
+		Recurrence: e.Recurrence.MoveTo(e.StartTime, cloneStart),
+	}
+	if e.Description != nil {
+		description := *e.Description
+		clone.Description = &description
+	}
+	if e.MeetingURL != nil {
+		meetingURL := *e.MeetingURL
+		clone.MeetingURL = &meetingURL
+	}
+	return clone
+}
@@ -1,55 +1,650 @@
 package models
 
 import (
+	"bytes"
 	"challenge/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
 )
 
 // CreateEventRequest represents the JSON payload for creating an event
+// Declarative rules live in the validate tags; cross-field rules are checked by IsValid,
+// which the request validator runs once the tags pass
 type CreateEventRequest struct {
-	Title       string  `json:"title"`
+	// ID is chosen by clients creating events offline, generated when omitted; checked by IsValid
+	ID          string  `json:"id,omitempty"`
+	Title       string  `json:"title" validate:"required,max=100"`
 	Description *string `json:"description,omitempty"`
-	StartTime   string  `json:"start_time"` // ISO 8601 format
-	EndTime     string  `json:"end_time"`   // ISO 8601 format
+	StartTime   string  `json:"start_time" validate:"required"`         // ISO 8601 format
+	EndTime     string  `json:"end_time,omitempty" validate:"required"` // ISO 8601 format, defaulted when DEFAULT_DURATION is set
+	Status      string  `json:"status,omitempty" validate:"omitempty,oneof=confirmed tentative cancelled"`
+	// Metadata is an arbitrary JSON object, checked by IsValid
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+	// Recurrence repeats the event, checked by IsValid
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// Links are http or https URLs, checked by IsValid
+	Links []string `json:"links,omitempty"`
+	// MeetingURL is an https URL, checked by IsValid
+	MeetingURL *string `json:"meeting_url,omitempty"`
+	// Priority is 0 (undefined) to 9, 0 when omitted
+	Priority *int `json:"priority,omitempty" validate:"omitempty,min=0,max=9"`
+	// Tags are labels for filtering, checked by IsValid
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ApplyTo copies the request fields onto an event
+// The request must have passed validation so its timestamps parse
+func (req *CreateEventRequest) ApplyTo(event *Event) {
+	startTime, _ := utils.ParseTimestamp(req.StartTime)
+	endTime, _ := utils.ParseTimestamp(req.EndTime)
+
+	event.Title = req.Title
+	event.Description = req.Description
+	event.StartTime = startTime
+	event.EndTime = endTime
+	event.Status = req.Status
+	if event.Status == "" {
+		event.Status = StatusConfirmed
+	}
+
+	event.Metadata = nil
+	if len(req.Metadata) > 0 {
+		_ = json.Unmarshal(req.Metadata, &event.Metadata)
+	}
+
+	event.Recurrence = req.Recurrence
+	event.Links = req.Links
+	event.MeetingURL = req.MeetingURL
+	event.Tags = req.Tags
+	event.Priority = 0
+	if req.Priority != nil {
+		event.Priority = *req.Priority
+	}
+}
+
+// Validate runs IsValid, letting the request validator check the cross-field rules
+func (req *CreateEventRequest) Validate(rules Rules) error {
+	return IsValid(req, rules)
+}
+
+// NullableString distinguishes an absent JSON member from an explicit null
+type NullableString struct {
+	// Set is true when the member was present, even if null
+	Set bool
+	// Value is nil for an explicit null
+	Value *string
+}
+
+// UnmarshalJSON is only called for members present in the payload
+func (n *NullableString) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
+
+// NullableStrings distinguishes an absent JSON list member from an explicit null
+type NullableStrings struct {
+	// Set is true when the member was present, even if null
+	Set bool
+	// Value is nil for an explicit null
+	Value []string
+}
+
+// UnmarshalJSON is only called for members present in the payload
+func (n *NullableStrings) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
+
+// PatchEventRequest represents the JSON payload for a partial update
+// Omitted fields keep their current value. With regular JSON a null value is treated
+// like an omitted one; with JSON Merge Patch (RFC 7396) a null description, metadata,
+// meeting_url, recurrence, links or tags clears it. Metadata and recurrence objects and the
+// links and tags lists replace the current ones as a whole.
+type PatchEventRequest struct {
+	Title       *string            `json:"title"`
+	Description NullableString     `json:"description"`
+	StartTime   *string            `json:"start_time"`
+	EndTime     *string            `json:"end_time"`
+	Status      *string            `json:"status"`
+	Metadata    json.RawMessage    `json:"metadata"`
+	Recurrence  NullableRecurrence `json:"recurrence"`
+	Links       NullableStrings    `json:"links"`
+	MeetingURL  NullableString     `json:"meeting_url"`
+	Priority    *int               `json:"priority"`
+	Tags        NullableStrings    `json:"tags"`
+
+	// MergePatch enables JSON Merge Patch semantics for null values
+	MergePatch bool `json:"-"`
+}
+
+// Merge returns the full request resulting from applying the patch to an event
+func (p *PatchEventRequest) Merge(event *Event) CreateEventRequest {
+	// Copied so applying the request to the same event doesn't reset it first
+	priority := event.Priority
+	req := CreateEventRequest{
+		Title:       event.Title,
+		Description: event.Description,
+		StartTime:   event.StartTime.Format(time.RFC3339Nano),
+		EndTime:     event.EndTime.Format(time.RFC3339Nano),
+		Status:      event.Status,
+		Recurrence:  event.Recurrence,
+		Links:       event.Links,
+		MeetingURL:  event.MeetingURL,
+		Priority:    &priority,
+		Tags:        event.Tags,
+	}
+	if event.Metadata != nil {
+		req.Metadata, _ = json.Marshal(event.Metadata)
+	}
+
+	if p.Title != nil {
+		req.Title = *p.Title
+	}
+	if p.Description.Value != nil || (p.Description.Set && p.MergePatch) {
+		req.Description = p.Description.Value
+	}
+	if p.StartTime != nil {
+		req.StartTime = *p.StartTime
+	}
+	if p.EndTime != nil {
+		req.EndTime = *p.EndTime
+	}
+	if p.Status != nil {
+		req.Status = *p.Status
+	}
+	if p.Metadata != nil && (!isJSONNull(p.Metadata) || p.MergePatch) {
+		req.Metadata = p.Metadata
+	}
+	if p.Recurrence.Value != nil || (p.Recurrence.Set && p.MergePatch) {
+		req.Recurrence = p.Recurrence.Value
+	}
+	if p.Links.Value != nil || (p.Links.Set && p.MergePatch) {
+		req.Links = p.Links.Value
+	}
+	if p.MeetingURL.Value != nil || (p.MeetingURL.Set && p.MergePatch) {
+		req.MeetingURL = p.MeetingURL.Value
+	}
+	if p.Priority != nil {
+		req.Priority = p.Priority
+	}
+	if p.Tags.Value != nil || (p.Tags.Set && p.MergePatch) {
+		req.Tags = p.Tags.Value
+	}
+	return req
+}
+
+// MaxBatchSize is the maximum number of IDs accepted by batch requests
+const MaxBatchSize = 200
+
+// MaxImportSize is the maximum number of events accepted by one import
+const MaxImportSize = 1000
+
+// Conflict modes of an import, chosen with ?on_conflict=
+const (
+	// OnConflictFail imports nothing if any event conflicts
+	OnConflictFail = "fail"
+	// OnConflictSkip imports only the events without conflicts
+	OnConflictSkip = "skip"
+	// OnConflictAllow imports every event and only reports the conflicts
+	OnConflictAllow = "allow"
+)
+
+// Statuses of the events of an import
+const (
+	ImportStatusImported = "imported"
+	// ImportStatusSkipped marks conflicting events left out with on_conflict=skip
+	ImportStatusSkipped = "skipped"
+	// ImportStatusConflict marks the conflicting events that failed an on_conflict=fail import
+	ImportStatusConflict = "conflict"
+	// ImportStatusAborted marks the events without conflicts of a failed import
+	ImportStatusAborted = "aborted"
+	// ImportStatusInvalid marks the events failing validation in a partial import
+	ImportStatusInvalid = "invalid"
+	// ImportStatusExists marks the events left out because their id is already taken
+	ImportStatusExists = "exists"
+)
+
+// Import modes, deciding what an invalid event does to the rest of the import
+const (
+	// ImportModeAtomic rejects the whole import with 400 when an event is invalid
+	ImportModeAtomic = "atomic"
+	// ImportModePartial imports the valid events and reports the invalid ones
+	ImportModePartial = "partial"
+)
+
+// ImportResult reports what happened to the event at Index in the import request
+// ConflictingIDs are the stored events it overlaps, ConflictingIndexes the other imported
+// events it overlaps. Invalid events of a partial import carry their validation failure
+// in Error, Code and Fields, shaped like a 400 response.
+type ImportResult struct {
+	Index              int         `json:"index"`
+	Status             string      `json:"status"`
+	ID                 *uuid.UUID  `json:"id,omitempty"`
+	Error              string      `json:"error,omitempty"`
+	Code               string      `json:"code,omitempty"`
+	Fields             FieldErrors `json:"fields,omitempty"`
+	ConflictingIDs     []uuid.UUID `json:"conflicting_ids"`
+	ConflictingIndexes []int       `json:"conflicting_indexes"`
+}
+
+// ImportResponse holds the number of imported events and the result of every event
+type ImportResponse struct {
+	Imported int             `json:"imported"`
+	Results  []*ImportResult `json:"results"`
+}
+
+// BatchGetRequest represents the JSON payload for fetching several events at once
+type BatchGetRequest struct {
+	IDs []string `json:"ids"`
 }
+
+// BatchGetResponse holds the events found and the IDs that didn't match any event
+type BatchGetResponse struct {
+	Events  []*Event    `json:"events"`
+	Missing []uuid.UUID `json:"missing"`
+}
+
+// ValidationError is a request validation failure
+// Code is stable and never translated so clients can branch on it; Message is the
+// English text, localized by the handlers
 type ValidationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// FieldError is a validation error, attributed to a single request field when Field is set
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
+// FieldErrors collects the field-level errors of a request
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	if len(e) == 0 {
+		return ""
+	}
+	return e[0].Message
+}
+
 const (
 	MaxTitleLength = 100
+	// MaxMetadataSize is the largest serialized metadata object accepted, in bytes
+	MaxMetadataSize = 4096
+	// MinPriority and MaxPriority bound the iCalendar PRIORITY values
+	MinPriority = 0
+	MaxPriority = 9
 )
 
 var (
-	TitleTooLong       = ValidationError{"title exceeds maximum length of 100 characters"}
-	TitleEmpty         = ValidationError{"title should not be empty"}
-	EndTimeBeforeStart = ValidationError{"end_time should be after start_time"}
-	InvalidTimeFormat  = ValidationError{"invalid time format, expected ISO 8601 format"}
+	TitleTooLong       = ValidationError{"TITLE_TOO_LONG", "title exceeds maximum length of 100 characters"}
+	TitleEmpty         = ValidationError{"TITLE_EMPTY", "title should not be empty"}
+	EndTimeBeforeStart = ValidationError{"END_BEFORE_START", "end_time should be after start_time"}
+	InvalidTimeFormat  = ValidationError{"INVALID_TIME_FORMAT", "invalid time format, expected ISO 8601 format"}
+	StartTimeRequired  = ValidationError{"START_TIME_REQUIRED", "start_time is required"}
+	EndTimeRequired    = ValidationError{"END_TIME_REQUIRED", "end_time is required"}
+	IDRequired         = ValidationError{"ID_REQUIRED", "id is required"}
+	CreatedAtRequired  = ValidationError{"CREATED_AT_REQUIRED", "created_at is required"}
+	InvalidStatus      = ValidationError{"INVALID_STATUS", "status must be one of confirmed, tentative or cancelled"}
+	MetadataNotObject  = ValidationError{"METADATA_NOT_OBJECT", "metadata must be a JSON object"}
+	MetadataTooLarge   = ValidationError{"METADATA_TOO_LARGE", "metadata exceeds maximum size of 4096 bytes"}
+	ZeroDuration       = ValidationError{"ZERO_DURATION", "end_time should not be equal to start_time"}
+	InvalidPriority    = ValidationError{"INVALID_PRIORITY", "priority must be between 0 and 9"}
+	InvalidTitle       = ValidationError{"INVALID_TITLE", "title must not contain control characters"}
+	InvalidDescription = ValidationError{"INVALID_DESCRIPTION", "description must not contain control characters other than line breaks and tabs"}
+	InvalidID          = ValidationError{"INVALID_ID", "id must be a UUID"}
+	MissingTimeZone    = ValidationError{"MISSING_TIME_ZONE", "timestamps must be RFC 3339 with a time zone offset such as Z or +02:00"}
+	YearOutOfRange     = ValidationError{"YEAR_OUT_OF_RANGE", "timestamps must fall within the supported year range"}
 )
 
+// yearError reports that the timestamp of field falls outside the supported year range
+func (r Rules) yearError(field string) FieldError {
+	return FieldError{
+		Field:   field,
+		Code:    YearOutOfRange.Code,
+		Message: fmt.Sprintf("%s must fall between the years %d and %d", field, r.MinYear, r.MaxYear),
+	}
+}
+
+// inYearRange reports whether t falls within MinYear and MaxYear
+func (r Rules) inYearRange(t time.Time) bool {
+	year := t.UTC().Year()
+	return year >= r.MinYear && year <= r.MaxYear
+}
+
+// timestampError attributes the error of a timestamp Rules.ParseTimestamp rejected to field
+// In strict mode naive timestamps get MissingTimeZone, everything else InvalidTimeFormat
+func timestampError(field string, err error) FieldError {
+	known := InvalidTimeFormat
+	if errors.Is(err, utils.ErrMissingOffset) {
+		known = MissingTimeZone
+	}
+	return FieldError{Field: field, Code: known.Code, Message: known.Message}
+}
+
+// parseTimes parses the start_time and end_time of a request, reporting a FieldError for
+// each one that doesn't parse
+func (r Rules) parseTimes(start, end string) (time.Time, time.Time, error) {
+	var errs FieldErrors
+
+	startTime, err := r.ParseTimestamp(start)
+	if err != nil {
+		errs = append(errs, timestampError("start_time", err))
+	}
+
+	endTime, err := r.ParseTimestamp(end)
+	if err != nil {
+		errs = append(errs, timestampError("end_time", err))
+	}
+
+	if errs != nil {
+		return time.Time{}, time.Time{}, errs
+	}
+	return startTime, endTime, nil
+}
+
+// checkTimes checks that start and end fall within the supported year range, reporting a
+// FieldError for each one that doesn't, and that end is after start, or equal to it when
+// the rules allow zero durations
+func (r Rules) checkTimes(start, end time.Time) error {
+	var errs FieldErrors
+	if !r.inYearRange(start) {
+		errs = append(errs, r.yearError("start_time"))
+	}
+	if !r.inYearRange(end) {
+		errs = append(errs, r.yearError("end_time"))
+	}
+	if errs != nil {
+		return errs
+	}
+
+	if end.Before(start) {
+		return &EndTimeBeforeStart
+	}
+	if !r.AllowZeroDuration && end.Equal(start) {
+		return &ZeroDuration
+	}
+	return nil
+}
+
+// descriptionControls are the control characters allowed in a description, which may span lines
+const descriptionControls = "\n\r\t"
+
+// hasControlCharacters reports whether s contains a control character not in allowed
+// Control characters break iCalendar lines and let values forge log entries
+func hasControlCharacters(s, allowed string) bool {
+	for _, r := range s {
+		if unicode.IsControl(r) && !strings.ContainsRune(allowed, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules are the configurable validation rules, set from the configuration by the server
+// that owns the validator
+type Rules struct {
+	// AllowZeroDuration accepts an end_time equal to start_time
+	AllowZeroDuration bool
+	// StrictTimestamps accepts only RFC 3339 timestamps with an explicit offset
+	StrictTimestamps bool
+	// MinYear and MaxYear bound the UTC year of start_time and end_time, inclusive
+	MinYear, MaxYear int
+}
+
+// DefaultRules returns the rules of the default configuration
+func DefaultRules() Rules {
+	return Rules{AllowZeroDuration: true, MinYear: 1970, MaxYear: 2100}
+}
+
+// ParseTimestamp parses a timestamp with utils.ParseStrictTimestamp when the rules are
+// strict, and with utils.ParseTimestamp otherwise
+func (r Rules) ParseTimestamp(timestamp string) (time.Time, error) {
+	if r.StrictTimestamps {
+		return utils.ParseStrictTimestamp(timestamp)
+	}
+	return utils.ParseTimestamp(timestamp)
+}
+
+// CodeInvalidField is the code of a field failing a validate tag without a dedicated error
+const CodeInvalidField = "INVALID_FIELD"
+
+// TagErrors maps a field and failed validate tag, as "field.tag", to its error
+var TagErrors = map[string]*ValidationError{
+	"title.required":       &TitleEmpty,
+	"title.max":            &TitleTooLong,
+	"start_time.required":  &StartTimeRequired,
+	"end_time.required":    &EndTimeRequired,
+	"status.oneof":         &InvalidStatus,
+	"priority.min":         &InvalidPriority,
+	"priority.max":         &InvalidPriority,
+	"target_date.required": &InvalidTargetDate,
+}
+
 func (m *ValidationError) Error() string {
 	return m.Message
 }
 
-func IsValid(event *CreateEventRequest) error {
+// isJSONNull reports whether data is the JSON null literal
+func isJSONNull(data json.RawMessage) bool {
+	return string(bytes.TrimSpace(data)) == "null"
+}
+
+// validateMetadata checks that metadata, when present, is a JSON object within MaxMetadataSize
+func validateMetadata(data json.RawMessage) error {
+	if len(data) == 0 || isJSONNull(data) {
+		return nil
+	}
+
+	if len(data) > MaxMetadataSize {
+		return &MetadataTooLarge
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return &MetadataNotObject
+	}
+	return nil
+}
+
+// eventFields are the fields IsValid and IsValidEvent check alike, once parsed
+type eventFields struct {
+	title       string
+	description *string
+	start, end  time.Time
+	priority    int
+	metadata    json.RawMessage
+	links       []string
+	meetingURL  *string
+	tags        []string
+	recurrence  *Recurrence
+}
+
+// validateFields checks the rules shared by create requests and complete events: control
+// characters in the title and description, the times, the priority, the shape of metadata,
+// the links, the meeting URL, the tags and the recurrence
+func (r Rules) validateFields(f eventFields) error {
+	if hasControlCharacters(f.title, "") {
+		return &InvalidTitle
+	}
+
+	if f.description != nil && hasControlCharacters(*f.description, descriptionControls) {
+		return &InvalidDescription
+	}
+
+	if err := r.checkTimes(f.start, f.end); err != nil {
+		return err
+	}
+
+	if f.priority < MinPriority || f.priority > MaxPriority {
+		return &InvalidPriority
+	}
+
+	if err := validateMetadata(f.metadata); err != nil {
+		return err
+	}
+
+	if err := validateLinks(f.links); err != nil {
+		return err
+	}
+
+	if err := validateMeetingURL(f.meetingURL); err != nil {
+		return err
+	}
+
+	if err := validateTags(f.tags); err != nil {
+		return err
+	}
+
+	if f.recurrence != nil {
+		return f.recurrence.Validate(f.start)
+	}
+	return nil
+}
+
+// IsValid checks the rules the validate tags can't express: the client chosen ID being a
+// UUID, the timestamp formats and the checks shared with IsValidEvent
+func IsValid(event *CreateEventRequest, rules Rules) error {
+	if event.ID != "" {
+		if _, err := uuid.Parse(event.ID); err != nil {
+			return FieldErrors{{Field: "id", Code: InvalidID.Code, Message: InvalidID.Message}}
+		}
+	}
+
+	startTime, endTime, err := rules.parseTimes(event.StartTime, event.EndTime)
+	if err != nil {
+		return err
+	}
+
+	priority := 0
+	if event.Priority != nil {
+		priority = *event.Priority
+	}
+	return rules.validateFields(eventFields{
+		title:       event.Title,
+		description: event.Description,
+		start:       startTime,
+		end:         endTime,
+		priority:    priority,
+		metadata:    event.Metadata,
+		links:       event.Links,
+		meetingURL:  event.MeetingURL,
+		tags:        event.Tags,
+		recurrence:  event.Recurrence,
+	})
+}
+
+// IsValidEvent checks a complete event, such as one read back from an export, with the
+// same field checks as IsValid
+func IsValidEvent(event *Event, rules Rules) error {
+	if event.ID == uuid.Nil {
+		return &IDRequired
+	}
+
 	if event.Title == "" {
 		return &TitleEmpty
 	}
 
-	if len(event.Title) > MaxTitleLength {
+	if utf8.RuneCountInString(event.Title) > MaxTitleLength {
 		return &TitleTooLong
 	}
-	startTime, err := utils.ParseTimestamp(event.StartTime)
-	if err != nil {
-		return &InvalidTimeFormat
+
+	if event.StartTime.IsZero() {
+		return &StartTimeRequired
 	}
 
-	endTime, err := utils.ParseTimestamp(event.EndTime)
-	if err != nil {
-		return &InvalidTimeFormat
+	if event.EndTime.IsZero() {
+		return &EndTimeRequired
 	}
 
-	if endTime.Before(startTime) {
-		return &EndTimeBeforeStart
+	if event.CreatedAt.IsZero() {
+		return &CreatedAtRequired
 	}
-	return nil
+
+	if !IsValidStatus(event.Status) {
+		return &InvalidStatus
+	}
+
+	var metadata json.RawMessage
+	if event.Metadata != nil {
+		data, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return &MetadataNotObject
+		}
+		metadata = data
+	}
+
+	return rules.validateFields(eventFields{
+		title:       event.Title,
+		description: event.Description,
+		start:       event.StartTime,
+		end:         event.EndTime,
+		priority:    event.Priority,
+		metadata:    metadata,
+		links:       event.Links,
+		meetingURL:  event.MeetingURL,
+		tags:        event.Tags,
+		recurrence:  event.Recurrence,
+	})
+}
+
+// ShiftRequest represents the JSON payload for moving several events in time
+// Offset is a Go duration such as "24h" or "-30m"
+type ShiftRequest struct {
+	IDs    []string `json:"ids"`
+	Offset string   `json:"offset"`
+}
+
+// ShiftResponse holds the shifted events, or the events that would conflict after shifting
+type ShiftResponse struct {
+	Events    []*Event `json:"events"`
+	Conflicts []*Event `json:"conflicts"`
+}
+
+// AdjacentEvents holds the events right before and after an event by start time
+// Either is null at the ends of the list
+type AdjacentEvents struct {
+	Previous *Event `json:"previous"`
+	Next     *Event `json:"next"`
+}
+
+// ClientIDMapping pairs the temporary ID an offline client gave an event with the ID the
+// server created it under, so the client can reconcile its local store
+// Event is left out when the client prefers minimal responses
+type ClientIDMapping struct {
+	ClientID string    `json:"client_id"`
+	ServerID uuid.UUID `json:"server_id"`
+	Event    *Event    `json:"event,omitempty"`
+}
+
+// RescheduleRequest represents the JSON payload for moving a single event to new times
+type RescheduleRequest struct {
+	StartTime string `json:"start_time" validate:"required"` // ISO 8601 format
+	EndTime   string `json:"end_time" validate:"required"`   // ISO 8601 format
+}
+
+// Validate checks the timestamp formats and that end_time is after start_time, or equal to
+// it when rules allow zero durations
+func (req *RescheduleRequest) Validate(rules Rules) error {
+	startTime, endTime, err := rules.parseTimes(req.StartTime, req.EndTime)
+	if err != nil {
+		return err
+	}
+	return rules.checkTimes(startTime, endTime)
+}
+
+// Times returns the parsed start and end times of a request that passed validation
+func (req *RescheduleRequest) Times() (time.Time, time.Time) {
+	startTime, _ := utils.ParseTimestamp(req.StartTime)
+	endTime, _ := utils.ParseTimestamp(req.EndTime)
+	return startTime, endTime
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// validRequest returns a create request passing validation, one hour long
+func validRequest() CreateEventRequest {
+	return CreateEventRequest{
+		Title:     "Planning",
+		StartTime: "2025-03-01T10:00:00Z",
+		EndTime:   "2025-03-01T11:00:00Z",
+	}
+}
+
+func TestIsValidZeroDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowZero bool
+		end       string
+		want      error
+	}{
+		{"equal times allowed", true, "2025-03-01T10:00:00Z", nil},
+		{"equal times rejected", false, "2025-03-01T10:00:00Z", &ZeroDuration},
+		{"equal instants in other offsets rejected", false, "2025-03-01T11:00:00+01:00", &ZeroDuration},
+		{"one nanosecond allowed", false, "2025-03-01T10:00:00.000000001Z", nil},
+		{"end before start rejected", true, "2025-03-01T09:59:59Z", &EndTimeBeforeStart},
+		{"end before start rejected without zero durations", false, "2025-03-01T09:59:59Z", &EndTimeBeforeStart},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			req.EndTime = tt.end
+
+			rules := DefaultRules()
+			rules.AllowZeroDuration = tt.allowZero
+			if err := IsValid(&req, rules); !errors.Is(err, tt.want) {
+				t.Errorf("IsValid = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRescheduleZeroDuration(t *testing.T) {
+	req := RescheduleRequest{StartTime: "2025-03-01T10:00:00Z", EndTime: "2025-03-01T10:00:00Z"}
+
+	rules := DefaultRules()
+	rules.AllowZeroDuration = true
+	if err := req.Validate(rules); err != nil {
+		t.Errorf("Validate with zero durations allowed = %v, want nil", err)
+	}
+	rules.AllowZeroDuration = false
+	if err := req.Validate(rules); !errors.Is(err, &ZeroDuration) {
+		t.Errorf("Validate with zero durations rejected = %v, want %v", err, &ZeroDuration)
+	}
+}
+
+func TestIsValidControlCharacters(t *testing.T) {
+	description := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		title       string
+		description *string
+		want        error
+	}{
+		{"plain title", "Team sync", nil, nil},
+		{"newline in title", "Team\nsync", nil, &InvalidTitle},
+		{"tab in title", "Team\tsync", nil, &InvalidTitle},
+		{"carriage return in title", "Team\r\nDTSTART:20250301", nil, &InvalidTitle},
+		{"escape in title", "Team \x1b[31msync", nil, &InvalidTitle},
+		{"line breaks and tabs in description", "Team sync", description("Agenda:\n\t- review\r\n\t- plan"), nil},
+		{"null byte in description", "Team sync", description("Agenda\x00"), &InvalidDescription},
+		{"escape in description", "Team sync", description("\x1b[2J"), &InvalidDescription},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			req.Title = tt.title
+			req.Description = tt.description
+			if err := IsValid(&req, DefaultRules()); !errors.Is(err, tt.want) {
+				t.Errorf("IsValid = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
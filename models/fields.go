@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EventFields is the whitelist of JSON field names that can be selected on an Event
+var EventFields = jsonFieldNames(reflect.TypeOf(Event{}))
+
+// jsonFieldNames returns the JSON names of the exported fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// ParseFields parses a comma separated list of field names
+// Returns an error naming the first field that is not in the whitelist
+func ParseFields(param string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !EventFields[field] {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// UniqueOnFields are the fields creation can match existing events on with unique_on
+var UniqueOnFields = []string{"title", "start_time", "end_time"}
+
+// ParseUniqueOn parses the comma separated unique_on fields
+// Returns an error naming the first field that is not one of UniqueOnFields
+func ParseUniqueOn(param string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(UniqueOnFields, field) {
+			return nil, fmt.Errorf("unknown unique_on field %q, expected %s", field, strings.Join(UniqueOnFields, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// SelectFields marshals an event into a map containing only the given fields
+func SelectFields(event *Event, fields []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var full map[string]interface{}
+	if err := json.Unmarshal(data, &full); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := full[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
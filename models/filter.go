@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SortField is a field a list can be sorted by, named like its column
+type SortField string
+
+// Sort fields
+const (
+	SortStartTime SortField = "start_time"
+	SortEndTime   SortField = "end_time"
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+	SortTitle     SortField = "title"
+	SortPriority  SortField = "priority"
+)
+
+// sortFields is the whitelist of fields a list can be sorted by, in alphabetical order
+var sortFields = []SortField{
+	SortCreatedAt,
+	SortEndTime,
+	SortPriority,
+	SortStartTime,
+	SortTitle,
+	SortUpdatedAt,
+}
+
+// Valid reports whether f is one of the sort fields
+func (f SortField) Valid() bool {
+	for _, field := range sortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSortField parses the sort query parameter, an empty value leaves it unset
+func ParseSortField(value string) (SortField, error) {
+	field := SortField(value)
+	if value != "" && !field.Valid() {
+		return "", fmt.Errorf("invalid sort field %q, expected one of %s", value, SortFieldNames())
+	}
+	return field, nil
+}
+
+// SortOrder is the direction a list is sorted in
+type SortOrder string
+
+// Sort orders
+const (
+	Asc  SortOrder = "asc"
+	Desc SortOrder = "desc"
+)
+
+// ParseSortOrder parses the order query parameter, an empty value leaves it unset
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(value); order {
+	case "", Asc, Desc:
+		return order, nil
+	}
+	return "", fmt.Errorf("invalid order %q, expected %s or %s", value, Asc, Desc)
+}
+
+// MetadataFilterPrefix prefixes the query parameters that filter on metadata keys
+const MetadataFilterPrefix = "meta."
+
+// metadataKeyPattern matches the metadata keys that can be filtered on
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsValidMetadataKey reports whether key is a simple identifier usable in a metadata filter
+func IsValidMetadataKey(key string) bool {
+	return metadataKeyPattern.MatchString(key)
+}
+
+// SortFieldNames returns the allowed sort fields as a sorted, comma separated list
+func SortFieldNames() string {
+	names := make([]string, len(sortFields))
+	for i, field := range sortFields {
+		names[i] = string(field)
+	}
+	return strings.Join(names, ", ")
+}
+
+// EventFilter narrows and orders the events returned by a list query
+// Zero values leave the corresponding criterion unset
+type EventFilter struct {
+	// Owner selects events created by the given user
+	Owner string
+	// Status selects events with the given status
+	Status string
+	// CreatedFrom selects events created at or after the given time
+	CreatedFrom time.Time
+	// CreatedTo selects events created before the given time
+	CreatedTo time.Time
+	// MinDuration selects events lasting at least the given duration
+	MinDuration time.Duration
+	// MaxDuration selects events lasting at most the given duration
+	MaxDuration time.Duration
+	// Metadata selects events whose metadata has every key set to the given value
+	// Keys must satisfy IsValidMetadataKey
+	Metadata map[string]string
+	// Tags selects events carrying every one of the given tags
+	Tags []string
+	// Sort is the field to order by (default SortStartTime)
+	Sort SortField
+	// Order is the direction to order in (default Asc)
+	Order SortOrder
+	// Limit caps the number of events returned, zero returns all of them
+	Limit int
+	// Offset skips that many events, only used together with Limit
+	Offset int
+}
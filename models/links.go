@@ -0,0 +1,57 @@
+package models
+
+import "net/url"
+
+const (
+	// MaxLinks is the maximum number of links an event may have
+	MaxLinks = 10
+	// MaxLinkLength is the longest link accepted, in bytes
+	MaxLinkLength = 2048
+)
+
+var (
+	TooManyLinks = ValidationError{"TOO_MANY_LINKS", "links exceeds maximum of 10 entries"}
+	InvalidLink  = ValidationError{"INVALID_LINK", "links must be absolute http or https URLs of at most 2048 characters"}
+	// InvalidMeetingURL is returned for a meeting_url that isn't an https URL
+	InvalidMeetingURL = ValidationError{"INVALID_MEETING_URL", "meeting_url must be an absolute https URL of at most 2048 characters"}
+)
+
+// isWebURL reports whether raw is an absolute URL with a host and one of the schemes
+func isWebURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLinks checks the number of links and that each one is an http or https URL
+func validateLinks(links []string) error {
+	if len(links) > MaxLinks {
+		return &TooManyLinks
+	}
+
+	for _, link := range links {
+		if len(link) > MaxLinkLength || !isWebURL(link, "http", "https") {
+			return &InvalidLink
+		}
+	}
+	return nil
+}
+
+// validateMeetingURL checks that the meeting URL, when set, is an https URL
+func validateMeetingURL(meetingURL *string) error {
+	if meetingURL == nil {
+		return nil
+	}
+
+	if len(*meetingURL) > MaxLinkLength || !isWebURL(*meetingURL, "https") {
+		return &InvalidMeetingURL
+	}
+	return nil
+}
@@ -1,17 +1,113 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Event statuses, following the iCalendar STATUS property
+const (
+	StatusConfirmed = "confirmed"
+	StatusTentative = "tentative"
+	StatusCancelled = "cancelled"
+)
+
+// TimeZoneFormat is the layout of Event.TimeZone
+const TimeZoneFormat = "-07:00"
+
+// ZoneOffset returns the UTC offset of t in the TimeZoneFormat layout
+func ZoneOffset(t time.Time) string {
+	return t.Format(TimeZoneFormat)
+}
+
+// IsValidStatus reports whether status is one of the allowed event statuses
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusConfirmed, StatusTentative, StatusCancelled:
+		return true
+	}
+	return false
+}
+
 // Event represents the database table structure
 type Event struct {
 	ID          uuid.UUID `json:"id"`
 	Title       string    `json:"title"`
-	Description *string   `json:"description,omitempty"`
+	Description *string   `json:"description,omitempty"` // nil, stored as NULL, when there is none; "" when sent empty
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
-	CreatedAt   time.Time `json:"created_at"`
+	// TimeZone is the UTC offset start_time was sent with, like +02:00. Times are stored in
+	// UTC and both start_time and end_time are rendered in this offset.
+	TimeZone  string     `json:"timezone,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	CreatedBy *string    `json:"created_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Status    string     `json:"status"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Metadata holds arbitrary integration data such as external system IDs
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Recurrence repeats the event, nil for a one-off event
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// Links are related documents such as agendas or meeting notes
+	Links []string `json:"links,omitempty"`
+	// MeetingURL is where a virtual meeting is joined, kept apart from Links so
+	// calendar clients can offer a join button
+	MeetingURL *string `json:"meeting_url,omitempty"`
+	// Priority follows the iCalendar PRIORITY property: 1 is the highest, 9 the lowest
+	// and 0 leaves it undefined
+	Priority int `json:"priority"`
+	// Tags are free-form labels used to filter events
+	Tags []string `json:"tags,omitempty"`
+}
+
+// EventTitle is a compact projection of an event for pickers and type-ahead lists
+type EventTitle struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// EventStats summarizes the stored events, not counting deleted ones
+type EventStats struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"by_status"`
+	// EarliestStartTime and LatestStartTime are nil when there are no events
+	EarliestStartTime *time.Time `json:"earliest_start_time"`
+	LatestStartTime   *time.Time `json:"latest_start_time"`
+	// AverageDurationMS is the mean duration in milliseconds, zero when there are no events
+	AverageDurationMS int64 `json:"average_duration_ms"`
+	// CreatedLast24h counts the events created in the 24 hours before the stats were computed
+	CreatedLast24h int `json:"created_last_24h"`
+}
+
+// CalendarEvent is an event in the shape of the FullCalendar event object
+// Start and End are ISO 8601 dates for all-day events and timestamps otherwise
+type CalendarEvent struct {
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Start  string    `json:"start"`
+	End    string    `json:"end"`
+	AllDay bool      `json:"allDay"`
+}
+
+// Audited operations on events
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records a change to an event: the fields it changed with their previous
+// and new values, who made it and when
+// OldValues is null for creations and NewValues for deletions
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	EventID   uuid.UUID       `json:"event_id"`
+	Operation string          `json:"operation"`
+	OldValues json.RawMessage `json:"old_values"`
+	NewValues json.RawMessage `json:"new_values"`
+	Actor     *string         `json:"actor"`
+	ChangedAt time.Time       `json:"changed_at"`
 }
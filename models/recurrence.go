@@ -0,0 +1,238 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Recurrence frequencies
+const (
+	FrequencyDaily = "daily"
+)
+
+// MaxExcludedDates is the largest number of occurrences a recurrence may exclude
+const MaxExcludedDates = 100
+
+// Recurrence repeats an event, such as a "daily 9am-5pm" availability block
+// Occurrences keep the local wall-clock times of the first one in TimeZone, so they
+// stay at 9am local time across DST transitions instead of drifting with the UTC offset
+type Recurrence struct {
+	// Frequency is how often the event repeats, only FrequencyDaily is supported
+	Frequency string `json:"frequency"`
+	// TimeZone is the IANA time zone the wall-clock times are kept in, e.g. Europe/Madrid
+	TimeZone string `json:"timezone"`
+	// Count limits the number of occurrences, zero doesn't limit them
+	Count int `json:"count,omitempty"`
+	// Until excludes the occurrences starting after it
+	Until *time.Time `json:"until,omitempty"`
+	// ExcludedDates are the start times of occurrences that don't take place, like the
+	// iCalendar EXDATE property. They still count towards Count.
+	ExcludedDates []time.Time `json:"excluded_dates,omitempty"`
+}
+
+var (
+	InvalidRecurrenceFrequency = ValidationError{"INVALID_RECURRENCE_FREQUENCY", "recurrence frequency must be daily"}
+	InvalidRecurrenceTimeZone  = ValidationError{"INVALID_RECURRENCE_TIMEZONE", "recurrence timezone must be an IANA time zone name"}
+	InvalidRecurrenceCount     = ValidationError{"INVALID_RECURRENCE_COUNT", "recurrence count must not be negative"}
+	RecurrenceUntilBeforeStart = ValidationError{"RECURRENCE_UNTIL_BEFORE_START", "recurrence until should be after start_time"}
+	InvalidRecurrenceExclusion = ValidationError{"INVALID_RECURRENCE_EXCLUDED_DATE", "recurrence excluded_dates must be at most 100 occurrence start times"}
+)
+
+// Validate checks the recurrence of an event starting at start
+func (r *Recurrence) Validate(start time.Time) error {
+	if r.Frequency != FrequencyDaily {
+		return &InvalidRecurrenceFrequency
+	}
+
+	if r.TimeZone == "" {
+		return &InvalidRecurrenceTimeZone
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return &InvalidRecurrenceTimeZone
+	}
+
+	if r.Count < 0 {
+		return &InvalidRecurrenceCount
+	}
+
+	if r.Until != nil && r.Until.Before(start) {
+		return &RecurrenceUntilBeforeStart
+	}
+
+	if len(r.ExcludedDates) > MaxExcludedDates {
+		return &InvalidRecurrenceExclusion
+	}
+	// An excluded date must be when an occurrence starts: on or after the first one, at its
+	// local wall-clock time
+	first := start.In(loc)
+	for _, excluded := range r.ExcludedDates {
+		local := excluded.In(loc)
+		occurrenceStart := time.Date(local.Year(), local.Month(), local.Day(),
+			first.Hour(), first.Minute(), first.Second(), first.Nanosecond(), loc)
+		if excluded.Before(start) || !occurrenceStart.Equal(excluded) {
+			return &InvalidRecurrenceExclusion
+		}
+	}
+	return nil
+}
+
+// excludes reports whether the occurrence starting at start is one of ExcludedDates
+func (r *Recurrence) excludes(start time.Time) bool {
+	for _, excluded := range r.ExcludedDates {
+		if excluded.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// NullableRecurrence distinguishes an absent JSON member from an explicit null
+type NullableRecurrence struct {
+	// Set is true when the member was present, even if null
+	Set bool
+	// Value is nil for an explicit null
+	Value *Recurrence
+}
+
+// UnmarshalJSON is only called for members present in the payload
+func (n *NullableRecurrence) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
+
+// Occurrences returns the occurrences of the event starting within [from, to), ordered by start
+// An event without recurrence has a single occurrence, itself. Each occurrence is a copy of
+// the event with its own start and end time.
+func (e *Event) Occurrences(from, to time.Time) []*Event {
+	if e.Recurrence == nil {
+		if e.StartTime.Before(from) || !e.StartTime.Before(to) {
+			return nil
+		}
+		return []*Event{e}
+	}
+
+	loc, err := time.LoadLocation(e.Recurrence.TimeZone)
+	if err != nil {
+		// Validation rejects unknown zones, so this is a zone removed from the tz database
+		loc = time.UTC
+	}
+
+	start := e.StartTime.In(loc)
+	end := e.EndTime.In(loc)
+	// Number of calendar days the event spans, so an overnight block ends on the next day
+	spanDays := daysBetween(start, end)
+
+	// Skip the occurrences that start long before the range. A DST shift moves an
+	// occurrence by at most a few hours, so starting one day early is enough.
+	first := 0
+	if from.After(start) {
+		first = int(from.Sub(start)/(24*time.Hour)) - 1
+		if first < 0 {
+			first = 0
+		}
+	}
+
+	var occurrences []*Event
+	for i := first; ; i++ {
+		if e.Recurrence.Count > 0 && i >= e.Recurrence.Count {
+			break
+		}
+
+		occurrenceStart := time.Date(start.Year(), start.Month(), start.Day()+i,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), loc)
+		if !occurrenceStart.Before(to) {
+			break
+		}
+		if e.Recurrence.Until != nil && occurrenceStart.After(*e.Recurrence.Until) {
+			break
+		}
+		if occurrenceStart.Before(from) || e.Recurrence.excludes(occurrenceStart) {
+			continue
+		}
+
+		occurrence := *e
+		occurrence.StartTime = occurrenceStart.UTC()
+		occurrence.EndTime = time.Date(start.Year(), start.Month(), start.Day()+i+spanDays,
+			end.Hour(), end.Minute(), end.Second(), end.Nanosecond(), loc).UTC()
+		occurrences = append(occurrences, &occurrence)
+	}
+	return occurrences
+}
+
+// LastEnd returns when the last occurrence of the event ends, false for a series without
+// count or until, which never ends. Excluded dates are ignored, so for a series whose last
+// occurrences are excluded it is later than the end of the last occurrence held.
+func (e *Event) LastEnd() (time.Time, bool) {
+	r := e.Recurrence
+	if r == nil {
+		return e.EndTime, true
+	}
+	if r.Count == 0 && r.Until == nil {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start := e.StartTime.In(loc)
+	end := e.EndTime.In(loc)
+
+	last := r.Count - 1
+	if r.Until != nil {
+		// The occurrence on the day of until is the last one, unless it starts after until
+		byUntil := daysBetween(start, r.Until.In(loc))
+		if time.Date(start.Year(), start.Month(), start.Day()+byUntil,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), loc).After(*r.Until) {
+			byUntil--
+		}
+		if r.Count == 0 || byUntil < last {
+			last = byUntil
+		}
+	}
+	return time.Date(start.Year(), start.Month(), start.Day()+last+daysBetween(start, end),
+		end.Hour(), end.Minute(), end.Second(), end.Nanosecond(), loc).UTC(), true
+}
+
+// MoveTo returns a copy of r for its event moving from start to newStart; nil stays nil
+// The until bound moves by the same elapsed time, and every excluded occurrence stays
+// excluded, at the wall-clock time the moved occurrences start at in the recurrence's zone
+func (r *Recurrence) MoveTo(start, newStart time.Time) *Recurrence {
+	if r == nil {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	first := start.In(loc)
+	newFirst := newStart.In(loc)
+
+	moved := *r
+	if r.Until != nil {
+		until := r.Until.Add(newStart.Sub(start))
+		moved.Until = &until
+	}
+	moved.ExcludedDates = nil
+	for _, excluded := range r.ExcludedDates {
+		i := daysBetween(first, excluded.In(loc))
+		moved.ExcludedDates = append(moved.ExcludedDates, time.Date(newFirst.Year(), newFirst.Month(), newFirst.Day()+i,
+			newFirst.Hour(), newFirst.Minute(), newFirst.Second(), newFirst.Nanosecond(), loc))
+	}
+	return &moved
+}
+
+// Occurrence is when one occurrence of a recurring event takes place
+type Occurrence struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// daysBetween returns the number of calendar days from the date of a to the date of b
+func daysBetween(a, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(dayB.Sub(dayA) / (24 * time.Hour))
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrencesKeepWallClockAcrossDST(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	// A 9am-5pm block the Friday before Spain moves to summer time on 2025-03-30
+	start := time.Date(2025, 3, 28, 9, 0, 0, 0, madrid)
+	event := &Event{
+		Title:      "Office hours",
+		StartTime:  start.UTC(),
+		EndTime:    time.Date(2025, 3, 28, 17, 0, 0, 0, madrid).UTC(),
+		Recurrence: &Recurrence{Frequency: FrequencyDaily, TimeZone: "Europe/Madrid", Count: 5},
+	}
+
+	occurrences := event.Occurrences(start.AddDate(0, 0, -1), start.AddDate(0, 0, 10))
+	if len(occurrences) != 5 {
+		t.Fatalf("got %d occurrences, want 5", len(occurrences))
+	}
+
+	for i, occurrence := range occurrences {
+		localStart, localEnd := occurrence.StartTime.In(madrid), occurrence.EndTime.In(madrid)
+		if day := start.AddDate(0, 0, i).Day(); localStart.Day() != day {
+			t.Errorf("occurrence %d starts on day %d, want %d", i, localStart.Day(), day)
+		}
+		if localStart.Hour() != 9 || localStart.Minute() != 0 {
+			t.Errorf("occurrence %d starts at %s local time, want 09:00", i, localStart.Format("15:04"))
+		}
+		if localEnd.Hour() != 17 || localEnd.Minute() != 0 {
+			t.Errorf("occurrence %d ends at %s local time, want 17:00", i, localEnd.Format("15:04"))
+		}
+	}
+
+	// The UTC offset changes from +01:00 to +02:00, so the UTC start moves an hour earlier
+	if before, after := occurrences[1].StartTime.UTC().Hour(), occurrences[3].StartTime.UTC().Hour(); before != 8 || after != 7 {
+		t.Errorf("UTC start hours = %d and %d, want 8 before and 7 after the transition", before, after)
+	}
+}
+
+func TestCloneMovesRecurrenceLikeShift(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	// A daily 9am series in winter, cloned to after Spain moves to summer time on 2025-03-30
+	start := time.Date(2025, 3, 20, 9, 0, 0, 0, madrid)
+	until := start.AddDate(0, 0, 5)
+	event := &Event{
+		Title:     "Standup",
+		StartTime: start.UTC(),
+		EndTime:   start.Add(15 * time.Minute).UTC(),
+		Recurrence: &Recurrence{
+			Frequency:     FrequencyDaily,
+			TimeZone:      "Europe/Madrid",
+			Until:         &until,
+			ExcludedDates: []time.Time{start.AddDate(0, 0, 2)},
+		},
+	}
+
+	date := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	clone := event.CloneTo(date, madrid)
+
+	wantStart := time.Date(2025, 4, 1, 9, 0, 0, 0, madrid)
+	if !clone.StartTime.Equal(wantStart) {
+		t.Fatalf("clone starts at %v, want %v", clone.StartTime, wantStart)
+	}
+	want := event.Recurrence.MoveTo(event.StartTime, clone.StartTime)
+	if !clone.Recurrence.Until.Equal(*want.Until) {
+		t.Errorf("clone until = %v, want %v as when shifting", clone.Recurrence.Until, want.Until)
+	}
+	if wantUntil := until.Add(clone.StartTime.Sub(event.StartTime)); !clone.Recurrence.Until.Equal(wantUntil) {
+		t.Errorf("clone until = %v, want %v, moved by the elapsed time", clone.Recurrence.Until, wantUntil)
+	}
+
+	// The excluded third occurrence is still the third one, at 9am summer time
+	wantExcluded := time.Date(2025, 4, 3, 9, 0, 0, 0, madrid)
+	if len(clone.Recurrence.ExcludedDates) != 1 || !clone.Recurrence.ExcludedDates[0].Equal(wantExcluded) {
+		t.Errorf("clone excluded dates = %v, want [%v]", clone.Recurrence.ExcludedDates, wantExcluded)
+	}
+	if err := clone.Recurrence.Validate(clone.StartTime); err != nil {
+		t.Errorf("clone recurrence is invalid: %v", err)
+	}
+}
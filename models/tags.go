@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTags is the maximum number of tags an event may have
+	MaxTags = 20
+	// MaxTagLength is the longest tag accepted, in characters
+	MaxTagLength = 50
+)
+
+var (
+	TooManyTags = ValidationError{"TOO_MANY_TAGS", "tags exceeds maximum of 20 entries"}
+	InvalidTag  = ValidationError{"INVALID_TAG", "tags must be distinct, non-empty and at most 50 characters, without surrounding spaces"}
+)
+
+// TagCount is a tag with the number of events carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// validateTags checks the number of tags and that each one is a distinct, trimmed label
+func validateTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return &TooManyTags
+	}
+
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag == "" || strings.TrimSpace(tag) != tag || utf8.RuneCountInString(tag) > MaxTagLength || seen[tag] {
+			return &InvalidTag
+		}
+		seen[tag] = true
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"bytes"
+	"challenge/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// actorContextKey is the context key holding the caller that mutations are attributed to
+type actorContextKey struct{}
+
+// WithActor returns a context whose mutations are attributed to actor in the audit log
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// actorFrom returns the actor of ctx, or nil when the caller is anonymous
+func actorFrom(ctx context.Context) *string {
+	actor, ok := ctx.Value(actorContextKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return &actor
+}
+
+// unaudited are the event fields left out of audit entries; every change sets updated_at
+// and the entry records its own time
+var unaudited = []string{"updated_at"}
+
+// eventFields returns the JSON fields of an event, nil for a nil event
+func eventFields(event *models.Event) (map[string]json.RawMessage, error) {
+	if event == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range unaudited {
+		delete(fields, name)
+	}
+	return fields, nil
+}
+
+// auditValues returns the stored old and new values of a change from old to new
+// Creations and deletions keep every field of the event; updates only the changed fields,
+// with null for a field that was unset or cleared
+func auditValues(old, new *models.Event) (interface{}, interface{}, error) {
+	oldFields, err := eventFields(old)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode audit values: %w", err)
+	}
+	newFields, err := eventFields(new)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode audit values: %w", err)
+	}
+
+	if oldFields != nil && newFields != nil {
+		changedOld := make(map[string]json.RawMessage)
+		changedNew := make(map[string]json.RawMessage)
+		for name, value := range oldFields {
+			if !bytes.Equal(value, newFields[name]) {
+				changedOld[name] = value
+				changedNew[name] = nullIfMissing(newFields[name])
+			}
+		}
+		for name, value := range newFields {
+			if _, ok := oldFields[name]; !ok {
+				changedOld[name] = nullIfMissing(nil)
+				changedNew[name] = value
+			}
+		}
+		oldFields, newFields = changedOld, changedNew
+	}
+
+	oldValues, err := encodeAuditFields(oldFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	newValues, err := encodeAuditFields(newFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldValues, newValues, nil
+}
+
+// nullIfMissing returns value, or JSON null for a field that isn't set
+func nullIfMissing(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
+
+// encodeAuditFields returns the stored form of audit values, NULL when there are none
+func encodeAuditFields(fields map[string]json.RawMessage) (interface{}, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit values: %w", err)
+	}
+	return string(data), nil
+}
+
+// recordAudit writes the audit entry of a change from old to new using the given executor,
+// which should be the transaction making the change
+// old is nil for creations and new for deletions
+func (db *Database) recordAudit(ctx context.Context, exec execer, id uuid.UUID, operation string, old, new *models.Event, at time.Time) error {
+	oldValues, newValues, err := auditValues(old, new)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.ExecContext(ctx, db.sql(`
+		INSERT INTO {prefix}event_audit (event_id, operation, old_values, new_values, actor, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`),
+		id.String(),
+		operation,
+		oldValues,
+		newValues,
+		actorFrom(ctx),
+		formatTime(at),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// currentEvent reads the stored event with the given ID inside tx, soft-deleted or not
+func (db *Database) currentEvent(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE id = ?
+	`
+
+	event, err := scanEvent(tx.QueryRowContext(ctx, db.sql(query), id.String()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	return event, nil
+}
+
+// GetEventHistory returns the audit trail of an event, oldest change first
+// The trail outlives the event, so deleted events still have one
+func (db *Database) GetEventHistory(ctx context.Context, id uuid.UUID) ([]*models.AuditEntry, error) {
+	defer db.observe(ctx, "GetEventHistory", eventIDAttr(id))()
+
+	query := `
+		SELECT id, event_id, operation, old_values, new_values, actor, changed_at
+		FROM {prefix}event_audit
+		WHERE event_id = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query), id.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var eventIDStr, changedAtStr string
+		var oldValues, newValues sql.NullString
+
+		if err := rows.Scan(&entry.ID, &eventIDStr, &entry.Operation, &oldValues, &newValues, &entry.Actor, &changedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+
+		entry.EventID, err = uuid.Parse(eventIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UUID: %w", err)
+		}
+		entry.ChangedAt, err = time.Parse(time.RFC3339Nano, changedAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse changed_at: %w", err)
+		}
+		if oldValues.Valid {
+			entry.OldValues = json.RawMessage(oldValues.String)
+		}
+		if newValues.Valid {
+			entry.NewValues = json.RawMessage(newValues.String)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+	return entries, nil
+}
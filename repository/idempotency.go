@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"challenge/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// IdempotencyKeyTTL is how long an idempotency key is remembered
+const IdempotencyKeyTTL = 24 * time.Hour
+
+var (
+	// ErrIdempotencyKeyNotFound is returned when a key is unknown or has expired
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	// ErrIdempotencyKeyExists is returned when a key was stored concurrently by another request
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+)
+
+// IdempotencyScope identifies an idempotency key. Keys are chosen by clients, so they are
+// only looked up for the caller and HTTP method that stored them
+type IdempotencyScope struct {
+	// Principal is the ID of the caller, empty when authentication is disabled
+	Principal string
+	Method    string
+	Key       string
+}
+
+// GetIdempotencyKey returns the ID of the event created or updated with the given key and
+// the fingerprint of that request
+// Keys older than IdempotencyKeyTTL are treated as unknown
+func (db *Database) GetIdempotencyKey(ctx context.Context, scope IdempotencyScope) (uuid.UUID, string, error) {
+	defer db.observe(ctx, "GetIdempotencyKey")()
+
+	query := `
+		SELECT event_id, request_hash
+		FROM {prefix}idempotency_keys
+		WHERE principal = ? AND method = ? AND key = ? AND created_at >= ?
+	`
+
+	cutoff := formatTime(time.Now().Add(-IdempotencyKeyTTL))
+
+	var idStr string
+	var requestHash sql.NullString
+	err := db.Reader.QueryRowContext(ctx, db.sql(query), scope.Principal, scope.Method, scope.Key, cutoff).Scan(&idStr, &requestHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, "", ErrIdempotencyKeyNotFound
+		}
+		return uuid.Nil, "", fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to parse UUID: %w", err)
+	}
+	return id, requestHash.String, nil
+}
+
+// InsertEventWithIdempotencyKey inserts an event and records the key that created it and the
+// fingerprint of the request in a single transaction, once check, if not nil, passes, purging
+// expired keys along the way
+func (db *Database) InsertEventWithIdempotencyKey(ctx context.Context, event *models.Event, scope IdempotencyScope, requestHash string, check InsertCheck) error {
+	defer db.observe(ctx, "InsertEventWithIdempotencyKey")()
+
+	now := time.Now().UTC()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			if err := db.runCheck(ctx, tx, check); err != nil {
+				return err
+			}
+			if err := db.insertEvent(ctx, tx, event); err != nil {
+				return err
+			}
+			return db.storeIdempotencyKey(ctx, tx, scope, event.ID, requestHash, now)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Debug("Event inserted successfully", "id", event.ID)
+	return nil
+}
+
+// UpdateEventWithIdempotencyKey updates an event like UpdateEvent and records the key and
+// the fingerprint of the update request in the same transaction, so a retry of the update
+// can be recognized even after the event changed
+func (db *Database) UpdateEventWithIdempotencyKey(ctx context.Context, event *models.Event, precondition func(*models.Event) bool, scope IdempotencyScope, requestHash string) error {
+	defer db.observe(ctx, "UpdateEventWithIdempotencyKey", eventIDAttr(event.ID))()
+
+	now := time.Now().UTC()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			if err := db.updateEvent(ctx, tx, event, precondition); err != nil {
+				return err
+			}
+			return db.storeIdempotencyKey(ctx, tx, scope, event.ID, requestHash, now)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Debug("Event updated successfully", "id", event.ID)
+	return nil
+}
+
+// storeIdempotencyKey records a key using the given transaction, purging expired keys along
+// the way
+func (db *Database) storeIdempotencyKey(ctx context.Context, tx *sql.Tx, scope IdempotencyScope, id uuid.UUID, requestHash string, now time.Time) error {
+	_, err := tx.ExecContext(ctx,
+		db.sql(`DELETE FROM {prefix}idempotency_keys WHERE created_at < ?`),
+		formatTime(now.Add(-IdempotencyKeyTTL)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		db.sql(`INSERT INTO {prefix}idempotency_keys (principal, method, key, event_id, created_at, request_hash) VALUES (?, ?, ?, ?, ?, ?)`),
+		scope.Principal,
+		scope.Method,
+		scope.Key,
+		id.String(),
+		formatTime(now),
+		requestHash,
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return ErrIdempotencyKeyExists
+		}
+		return fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+	return nil
+}
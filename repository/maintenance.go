@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Vacuum rebuilds the database file to give the space of deleted rows back to the file
+// system, then truncates the write-ahead log. VACUUM can't run inside a transaction, so it
+// runs directly on the pool and blocks other writers until it's done.
+func (db *Database) Vacuum(ctx context.Context) error {
+	defer db.observe(ctx, "Vacuum")()
+
+	return db.retryBusy(ctx, func() error {
+		if _, err := db.DB.ExecContext(ctx, "VACUUM"); err != nil {
+			return fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		if _, err := db.DB.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			return fmt.Errorf("failed to checkpoint write-ahead log: %w", err)
+		}
+		return nil
+	})
+}
+
+// Analyze refreshes the statistics the query planner uses to pick indexes, and with optimize
+// also runs PRAGMA optimize, which re-analyzes only the tables whose statistics look stale
+func (db *Database) Analyze(ctx context.Context, optimize bool) error {
+	defer db.observe(ctx, "Analyze")()
+
+	return db.retryBusy(ctx, func() error {
+		if _, err := db.DB.ExecContext(ctx, "ANALYZE"); err != nil {
+			return fmt.Errorf("failed to analyze database: %w", err)
+		}
+		if optimize {
+			if _, err := db.DB.ExecContext(ctx, "PRAGMA optimize"); err != nil {
+				return fmt.Errorf("failed to optimize database: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Backup writes a consistent copy of the database to path with VACUUM INTO and returns its
+// size in bytes. The copy is written next to path first and renamed over it once complete,
+// so an earlier backup stays intact if this one fails. It reads through the write
+// connection, which holds off other writes until the copy is done.
+func (db *Database) Backup(ctx context.Context, path string) (int64, error) {
+	defer db.observe(ctx, "Backup")()
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("unable to create backup directory %s: %w", dir, err)
+	}
+
+	// VACUUM INTO refuses to overwrite a file, even one left by an interrupted backup
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("failed to remove incomplete backup: %w", err)
+	}
+
+	err := db.retryBusy(ctx, func() error {
+		if _, err := db.DB.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+			return fmt.Errorf("failed to back up database: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to replace backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get backup size: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// DatabaseSize returns the bytes the database takes on disk, its main file and
+// write-ahead log together. An in-memory database reports the size of its pages.
+func (db *Database) DatabaseSize(ctx context.Context) (int64, error) {
+	defer db.observe(ctx, "DatabaseSize")()
+
+	rows, err := db.Reader.QueryContext(ctx, "PRAGMA database_list")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list databases: %w", err)
+	}
+	defer rows.Close()
+
+	var file string
+	for rows.Next() {
+		var seq int
+		var name, path string
+		if err := rows.Scan(&seq, &name, &path); err != nil {
+			return 0, fmt.Errorf("failed to scan database: %w", err)
+		}
+		if name == "main" {
+			file = path
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating databases: %w", err)
+	}
+
+	if file == "" {
+		var pageCount, pageSize int64
+		if err := db.Reader.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+			return 0, fmt.Errorf("failed to get page count: %w", err)
+		}
+		if err := db.Reader.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+			return 0, fmt.Errorf("failed to get page size: %w", err)
+		}
+		return pageCount * pageSize, nil
+	}
+
+	var size int64
+	for _, path := range []string{file, file + "-wal"} {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get database size: %w", err)
+		}
+		size += info.Size()
+	}
+	return size, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations are applied in order on top of the base schema created by CreateTable
+// The version of a migration is its index in this slice plus one; never reorder or edit
+// an existing entry, append a new one instead
+var migrations = []string{
+	// 1: event ownership
+	`
+	ALTER TABLE {prefix}events ADD COLUMN created_by TEXT;
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_created_by ON {prefix}events(created_by);
+	`,
+	// 2: pad second-precision timestamps to the fixed-width nanosecond timeFormat
+	`
+	UPDATE {prefix}events SET start_time = substr(start_time, 1, 19) || '.000000000' || substr(start_time, 20)
+	WHERE instr(start_time, '.') = 0;
+	UPDATE {prefix}events SET end_time = substr(end_time, 1, 19) || '.000000000' || substr(end_time, 20)
+	WHERE instr(end_time, '.') = 0;
+	UPDATE {prefix}events SET created_at = substr(created_at, 1, 19) || '.000000000' || substr(created_at, 20)
+	WHERE instr(created_at, '.') = 0;
+	UPDATE {prefix}idempotency_keys SET created_at = substr(created_at, 1, 19) || '.000000000' || substr(created_at, 20)
+	WHERE instr(created_at, '.') = 0;
+	`,
+	// 3: last modification time
+	`
+	ALTER TABLE {prefix}events ADD COLUMN updated_at DATETIME;
+	UPDATE {prefix}events SET updated_at = created_at;
+	`,
+	// 4: event status
+	`
+	ALTER TABLE {prefix}events ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed';
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_status ON {prefix}events(status);
+	`,
+	// 5: sorting by modification and creation time
+	`
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_updated_at ON {prefix}events(updated_at);
+	`,
+	// 6: soft delete
+	`
+	ALTER TABLE {prefix}events ADD COLUMN deleted_at DATETIME;
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_deleted_at ON {prefix}events(deleted_at);
+	`,
+	// 7: filtering by creation time
+	`
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_created_at ON {prefix}events(created_at);
+	`,
+	// 8: integration metadata, a JSON object
+	`
+	ALTER TABLE {prefix}events ADD COLUMN metadata TEXT;
+	`,
+	// 9: daily recurrence, a JSON object
+	`
+	ALTER TABLE {prefix}events ADD COLUMN recurrence TEXT;
+	`,
+	// 10: related links, a JSON array of URLs
+	`
+	ALTER TABLE {prefix}events ADD COLUMN links TEXT;
+	`,
+	// 11: video-conference URL
+	`
+	ALTER TABLE {prefix}events ADD COLUMN meeting_url TEXT;
+	`,
+	// 12: priority, 0 to 9 as in iCalendar
+	`
+	ALTER TABLE {prefix}events ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_priority ON {prefix}events(priority);
+	`,
+	// 13: audit trail of event changes, old and new values are JSON objects
+	`
+	CREATE TABLE IF NOT EXISTS {prefix}event_audit (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id TEXT NOT NULL,
+		operation TEXT NOT NULL,
+		old_values TEXT,
+		new_values TEXT,
+		actor TEXT,
+		changed_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS {prefix}idx_event_audit_event_id ON {prefix}event_audit(event_id);
+	`,
+	// 14: tags, a JSON array of strings
+	`
+	ALTER TABLE {prefix}events ADD COLUMN tags TEXT;
+	`,
+	// 15: tags normalized into a table for filtering and counting, backfilled from the JSON column
+	`
+	CREATE TABLE IF NOT EXISTS {prefix}event_tags (
+		event_id TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (event_id, tag)
+	);
+	CREATE INDEX IF NOT EXISTS {prefix}idx_event_tags_tag ON {prefix}event_tags(tag, event_id);
+	INSERT OR IGNORE INTO {prefix}event_tags (event_id, tag)
+	SELECT {prefix}events.id, tag.value
+	FROM {prefix}events, json_each({prefix}events.tags) AS tag
+	WHERE {prefix}events.tags IS NOT NULL;
+	`,
+	// 16: fingerprint of the update request an idempotency key was used for, NULL for creations
+	`
+	ALTER TABLE {prefix}idempotency_keys ADD COLUMN request_hash TEXT;
+	`,
+	// 17: timestamps normalized to UTC, the offset start_time was sent with kept in timezone
+	// The fixed-width timeFormat puts the fraction at 20-29 and the offset from 30. Idempotency
+	// keys and audit entries are converted too, since their TTL and since cutoffs are UTC.
+	`
+	ALTER TABLE {prefix}events ADD COLUMN timezone TEXT;
+	UPDATE {prefix}events SET timezone = CASE WHEN substr(start_time, 30) = 'Z' THEN '+00:00' ELSE substr(start_time, 30) END;
+	UPDATE {prefix}events SET
+		start_time = strftime('%Y-%m-%dT%H:%M:%S', start_time) || substr(start_time, 20, 10) || 'Z',
+		end_time = strftime('%Y-%m-%dT%H:%M:%S', end_time) || substr(end_time, 20, 10) || 'Z',
+		created_at = strftime('%Y-%m-%dT%H:%M:%S', created_at) || substr(created_at, 20, 10) || 'Z',
+		updated_at = strftime('%Y-%m-%dT%H:%M:%S', updated_at) || substr(updated_at, 20, 10) || 'Z',
+		deleted_at = strftime('%Y-%m-%dT%H:%M:%S', deleted_at) || substr(deleted_at, 20, 10) || 'Z';
+	UPDATE {prefix}idempotency_keys SET
+		created_at = strftime('%Y-%m-%dT%H:%M:%S', created_at) || substr(created_at, 20, 10) || 'Z';
+	UPDATE {prefix}event_audit SET
+		changed_at = strftime('%Y-%m-%dT%H:%M:%S', changed_at) || substr(changed_at, 20, 10) || 'Z';
+	`,
+	// 18: idempotency keys scoped to the caller and HTTP method that used them
+	// Existing keys are attributed to the event's creator, a creation when they have no request
+	// fingerprint and an update otherwise. Creations weren't fingerprinted, so a retry of one
+	// within the TTL answers 422 instead of being replayed.
+	`
+	CREATE TABLE {prefix}idempotency_keys_scoped (
+		principal TEXT NOT NULL DEFAULT '',
+		method TEXT NOT NULL,
+		key TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		request_hash TEXT,
+		PRIMARY KEY (principal, method, key)
+	);
+	INSERT INTO {prefix}idempotency_keys_scoped (principal, method, key, event_id, created_at, request_hash)
+	SELECT
+		COALESCE((SELECT created_by FROM {prefix}events WHERE {prefix}events.id = keys.event_id), ''),
+		CASE WHEN keys.request_hash IS NULL THEN 'POST' ELSE 'PUT' END,
+		keys.key, keys.event_id, keys.created_at, keys.request_hash
+	FROM {prefix}idempotency_keys AS keys;
+	DROP TABLE {prefix}idempotency_keys;
+	ALTER TABLE {prefix}idempotency_keys_scoped RENAME TO {prefix}idempotency_keys;
+	`,
+}
+
+// migrate applies every migration newer than the recorded schema version
+func (db *Database) migrate(ctx context.Context) error {
+	_, err := db.DB.ExecContext(ctx, db.sql(`
+		CREATE TABLE IF NOT EXISTS {prefix}schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at DATETIME NOT NULL
+		)
+	`))
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := db.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		err := db.withTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, db.sql(migrations[i])); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				db.sql(`INSERT INTO {prefix}schema_migrations (version, applied_at) VALUES (?, ?)`),
+				version,
+				formatTime(time.Now()),
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+		db.logger.Info("Applied migration", "version", version)
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the version of the most recently applied migration
+func (db *Database) SchemaVersion(ctx context.Context) (int, error) {
+	defer db.observe(ctx, "SchemaVersion")()
+
+	var version int
+	err := db.Reader.QueryRowContext(ctx,
+		db.sql(`SELECT COALESCE(MAX(version), 0) FROM {prefix}schema_migrations`),
+	).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion returns the version the schema has once every migration is applied
+func LatestSchemaVersion() int {
+	return len(migrations)
+}
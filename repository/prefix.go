@@ -0,0 +1,11 @@
+package repository
+
+import "strings"
+
+// prefixPlaceholder marks where the table prefix goes in table and index names
+const prefixPlaceholder = "{prefix}"
+
+// sql returns query with the table prefix substituted for every {prefix} placeholder
+func (db *Database) sql(query string) string {
+	return strings.ReplaceAll(query, prefixPlaceholder, db.tablePrefix)
+}
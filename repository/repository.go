@@ -1,66 +1,236 @@
 package repository
 
 import (
+	"challenge/config"
 	"challenge/models"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
-// Database holds the database connection
+// timeFormat is how timestamps are stored: RFC3339Nano with a fixed-width fraction
+// so sub-second precision round-trips and stored values sort chronologically as text
+const timeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime returns the stored form of t, always in UTC so instants sent with different
+// offsets compare and sort as text in the right order
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// inZone returns t in the fixed zone of a stored timezone offset such as +02:00, or as is
+// when there's none, so events are rendered with the offset they were sent with
+func inZone(t time.Time, offset string) (time.Time, error) {
+	if offset == "" {
+		return t, nil
+	}
+	parsed, err := time.Parse(models.TimeZoneFormat, offset)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", offset, err)
+	}
+	if _, seconds := parsed.Zone(); seconds != 0 {
+		return t.In(time.FixedZone("", seconds)), nil
+	}
+	return t.UTC(), nil
+}
+
+// maxResults caps the events returned by an unpaginated GetAllEvents so a huge table
+// can't exhaust memory
+const maxResults = 10000
+
+// ErrEventNotFound is returned when no event matches the requested ID
+var ErrEventNotFound = errors.New("event not found")
+
+// ErrEventExists is returned when inserting an event whose ID is already taken, also by a
+// soft-deleted event
+var ErrEventExists = errors.New("event already exists")
+
+// ErrPreconditionFailed is returned when the stored event doesn't satisfy the precondition
+// of a conditional write, typically because it changed since the client read it
+var ErrPreconditionFailed = errors.New("event precondition failed")
+
+// Database holds the database connections
 type Database struct {
+	// DB is the write connection; SQLite allows a single writer, so it holds one connection
+	// and every write and transaction goes through it
 	DB *sql.DB
+	// Reader is the pool that reads outside a transaction go through, so they don't queue
+	// behind writes. WAL lets them run while a write is in progress. It is DB itself for an
+	// in-memory database, which only exists on its one connection.
+	Reader *sql.DB
+
+	// busyRetries is how many times writes are retried on SQLITE_BUSY
+	busyRetries int
+	// tablePrefix is prepended to every table and index name
+	tablePrefix string
+	// slowQueryThreshold is how long a query may take before it is logged, zero disables it
+	slowQueryThreshold time.Duration
+	// timeOrderedIDs generates version 7 event IDs instead of random version 4 ones
+	timeOrderedIDs bool
+	// logger receives the repository's log lines; per-event ones are at debug level
+	logger *slog.Logger
 }
 
-// NewDatabase creates a new database connection
-func NewDatabase(ctx context.Context, dbPath string) (*Database, error) {
+// NewDatabase creates a new database connection logging to logger
+func NewDatabase(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Database, error) {
+	if !config.ValidTablePrefix(cfg.TablePrefix) {
+		return nil, fmt.Errorf("invalid table prefix %q, only letters, digits and _ are allowed", cfg.TablePrefix)
+	}
+
+	dbPath := cfg.DBPath
+	memory := IsMemoryPath(dbPath)
+	if !memory {
+		if err := prepareDBPath(dbPath); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open database: %w", err)
 	}
 
-	// Optional: Configure connection pool settings
-	db.SetMaxOpenConns(1) // SQLite works best with single connection for writes
+	// Writes go through a single connection since SQLite allows one writer at a time.
+	// A single connection that is never recycled also keeps an in-memory database alive,
+	// since each new connection to :memory: would open a fresh, empty database.
+	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
 	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
 
 	// Verify connection
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("unable to ping database: %w", err)
 	}
 
 	// Enable foreign keys and WAL mode for better concurrency
 	_, err = db.Exec("PRAGMA foreign_keys = ON")
 	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
-	_, err = db.Exec("PRAGMA journal_mode = WAL")
+	// WAL is meaningless for an in-memory database
+	if !memory {
+		_, err = db.Exec("PRAGMA journal_mode = WAL")
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+		}
+	}
+
+	reader := db
+	if !memory {
+		reader, err = openReader(ctx, dbPath, cfg)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	if memory {
+		logger.Info("Successfully connected to in-memory SQLite database")
+	} else {
+		logger.Info("Successfully connected to SQLite database", "path", dbPath)
+	}
+
+	return &Database{
+		DB:          db,
+		Reader:      reader,
+		busyRetries: cfg.BusyRetries,
+		tablePrefix: cfg.TablePrefix,
+
+		slowQueryThreshold: time.Duration(cfg.SlowQueryMS) * time.Millisecond,
+		timeOrderedIDs:     cfg.UUIDVersion == 7,
+		logger:             logger,
+	}, nil
+}
+
+// NewID generates an event ID, a random version 4 UUID or, with UUID_VERSION=7, a version 7
+// one whose leading bits are the creation time, so new rows land at the end of the index
+func (db *Database) NewID() uuid.UUID {
+	if db.timeOrderedIDs {
+		return uuid.Must(uuid.NewV7())
+	}
+	return uuid.New()
+}
+
+// openReader opens the pool of read connections to the database file at dbPath
+// Its connections are query-only, so a write sent to the wrong pool fails instead of
+// competing with the write connection for the lock.
+func openReader(ctx context.Context, dbPath string, cfg config.Config) (*sql.DB, error) {
+	dsn := dbPath + "?_query_only=true"
+	if strings.Contains(dbPath, "?") {
+		dsn = dbPath + "&_query_only=true"
+	}
+
+	reader, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+		return nil, fmt.Errorf("unable to open database for reading: %w", err)
+	}
+
+	reader.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	reader.SetMaxIdleConns(cfg.DBMaxIdleConns)
+
+	if err := reader.PingContext(ctx); err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("unable to ping database for reading: %w", err)
 	}
+	return reader, nil
+}
+
+// IsMemoryPath reports whether dbPath opens an in-memory SQLite database
+func IsMemoryPath(dbPath string) bool {
+	return dbPath == ":memory:" || strings.Contains(dbPath, "mode=memory")
+}
 
-	log.Println("Successfully connected to SQLite database")
+// prepareDBPath makes sure the database file can be created at dbPath
+// The parent directory is created when missing
+func prepareDBPath(dbPath string) error {
+	info, err := os.Stat(dbPath)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("database path %s is a directory", dbPath)
+		}
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to access database path %s: %w", dbPath, err)
+	}
 
-	return &Database{DB: db}, nil
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create database directory %s: %w", dir, err)
+	}
+	return nil
 }
 
-// Close closes the database connection
+// Close closes the database connections
 func (db *Database) Close() {
+	if db.Reader != db.DB {
+		db.Reader.Close()
+	}
 	db.DB.Close()
-	log.Println("Database connection closed")
+	db.logger.Info("Database connection closed")
 }
 
 // CreateTable creates the events table if it doesn't exist
 func (db *Database) CreateTable(ctx context.Context) error {
 	query := `
-	CREATE TABLE IF NOT EXISTS events (
+	CREATE TABLE IF NOT EXISTS {prefix}events (
 		id TEXT PRIMARY KEY,
 		title TEXT NOT NULL CHECK(length(title) <= 100),
 		description TEXT,
@@ -69,79 +239,366 @@ func (db *Database) CreateTable(ctx context.Context) error {
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 	
-	CREATE INDEX IF NOT EXISTS idx_events_start_time ON events(start_time);
-	CREATE INDEX IF NOT EXISTS idx_events_end_time ON events(end_time);
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_start_time ON {prefix}events(start_time);
+	CREATE INDEX IF NOT EXISTS {prefix}idx_events_end_time ON {prefix}events(end_time);
+
+	CREATE TABLE IF NOT EXISTS {prefix}idempotency_keys (
+		key TEXT PRIMARY KEY,
+		event_id TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
 	`
 
-	_, err := db.DB.ExecContext(ctx, query)
+	_, err := db.DB.ExecContext(ctx, db.sql(query))
 	if err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
 
-	log.Println("Table 'events' is ready")
+	if err := db.migrate(ctx); err != nil {
+		return err
+	}
+
+	db.logger.Info("Table is ready", "table", db.tablePrefix+"events")
+	return nil
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// rowQueryer is implemented by both *sql.DB and *sql.Tx
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back on error
+func (db *Database) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// BookingCounts counts the bookings the booking policy limits
+type BookingCounts interface {
+	CountEventsInRange(ctx context.Context, from, to time.Time, exclude ...uuid.UUID) (int, error)
+	CountEventsByOwner(ctx context.Context, owner string) (int, error)
+}
+
+// InsertCheck verifies that an event may be inserted, counting the bookings inside the
+// transaction inserting it. Writes go through a single connection, so the counts include
+// every committed insert and concurrent inserts can't both pass a limit. Its error aborts
+// the insert and is returned as is.
+type InsertCheck func(ctx context.Context, counts BookingCounts) error
+
+// txCounts counts bookings inside a transaction
+type txCounts struct {
+	db *Database
+	tx *sql.Tx
+}
+
+func (c txCounts) CountEventsInRange(ctx context.Context, from, to time.Time, exclude ...uuid.UUID) (int, error) {
+	defer c.db.observe(ctx, "CountEventsInRange")()
+	return c.db.countEventsInRange(ctx, c.tx, from, to, exclude)
+}
+
+func (c txCounts) CountEventsByOwner(ctx context.Context, owner string) (int, error) {
+	defer c.db.observe(ctx, "CountEventsByOwner")()
+	return c.db.countEventsByOwner(ctx, c.tx, owner)
+}
+
+// runCheck runs check, if not nil, inside tx
+func (db *Database) runCheck(ctx context.Context, tx *sql.Tx, check InsertCheck) error {
+	if check == nil {
+		return nil
+	}
+	return check(ctx, txCounts{db: db, tx: tx})
+}
+
+// InsertEvent inserts a new event into the database, once check, if not nil, passes
+func (db *Database) InsertEvent(ctx context.Context, event *models.Event, check InsertCheck) error {
+	defer db.observe(ctx, "InsertEvent")()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			if err := db.runCheck(ctx, tx, check); err != nil {
+				return err
+			}
+			return db.insertEvent(ctx, tx, event)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Debug("Event inserted successfully", "id", event.ID)
+	return nil
+}
+
+// FindMatchingEvent retrieves a stored event with the same value as event for every one of
+// fields, which are models.UniqueOnFields, or ErrEventNotFound when there is none.
+// Timestamps match when they are the same instant, whatever offset they were sent with.
+func (db *Database) FindMatchingEvent(ctx context.Context, event *models.Event, fields []string) (*models.Event, error) {
+	defer db.observe(ctx, "FindMatchingEvent")()
+
+	return db.findMatchingEvent(ctx, db.Reader, event, fields)
+}
+
+// findMatchingEvent looks up the event matching event on fields using the given queryer
+func (db *Database) findMatchingEvent(ctx context.Context, q rowQueryer, event *models.Event, fields []string) (*models.Event, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	for _, field := range fields {
+		switch field {
+		case "title":
+			args = append(args, event.Title)
+		case "start_time":
+			args = append(args, formatTime(event.StartTime))
+		case "end_time":
+			args = append(args, formatTime(event.EndTime))
+		default:
+			return nil, fmt.Errorf("unsupported unique field %q", field)
+		}
+		// field is one of the cases above, so it's safe to use as the column name
+		conditions = append(conditions, field+" = ?")
+	}
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at ASC, id ASC
+		LIMIT 1
+	`
+
+	match, err := scanEvent(q.QueryRowContext(ctx, db.sql(query), args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to find matching event: %w", err)
+	}
+	return match, nil
+}
+
+// InsertEventUnlessExists inserts event unless a stored event matches it on fields, like
+// FindMatchingEvent, checking and inserting in a single transaction so concurrent requests
+// can't both insert. Without a match check, if not nil, runs before the insert. Returns the
+// matching event and false, or event and true once inserted.
+func (db *Database) InsertEventUnlessExists(ctx context.Context, event *models.Event, fields []string, check InsertCheck) (*models.Event, bool, error) {
+	defer db.observe(ctx, "InsertEventUnlessExists")()
+
+	var match *models.Event
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			var err error
+			match, err = db.findMatchingEvent(ctx, tx, event, fields)
+			if err == nil || !errors.Is(err, ErrEventNotFound) {
+				return err
+			}
+			match = nil
+			if err := db.runCheck(ctx, tx, check); err != nil {
+				return err
+			}
+			return db.insertEvent(ctx, tx, event)
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if match != nil {
+		return match, false, nil
+	}
+	db.logger.Debug("Event inserted successfully", "id", event.ID)
+	return event, true, nil
+}
+
+// InsertEvents inserts several new events in a single transaction
+// Either every event is inserted or none is
+func (db *Database) InsertEvents(ctx context.Context, events []*models.Event) error {
+	defer db.observe(ctx, "InsertEvents")()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			for _, event := range events {
+				if err := db.insertEvent(ctx, tx, event); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Info("Inserted events", "count", len(events))
 	return nil
 }
 
-// InsertEvent inserts a new event into the database
-func (db *Database) InsertEvent(ctx context.Context, event *models.Event) error {
+// insertEvent inserts a new event and its audit entry using the given executor,
+// which should be a transaction
+func (db *Database) insertEvent(ctx context.Context, exec execer, event *models.Event) error {
 	// Generate UUID if not provided
 	if event.ID == uuid.Nil {
-		event.ID = uuid.New()
+		event.ID = db.NewID()
+	}
+
+	if event.Status == "" {
+		event.Status = models.StatusConfirmed
 	}
 
 	// Set created_at if not provided
 	if event.CreatedAt.IsZero() {
 		event.CreatedAt = time.Now()
 	}
+	if event.UpdatedAt.IsZero() {
+		event.UpdatedAt = event.CreatedAt
+	}
+
+	metadata, err := encodeMetadata(event.Metadata)
+	if err != nil {
+		return err
+	}
+	recurrence, err := encodeRecurrence(event.Recurrence)
+	if err != nil {
+		return err
+	}
+	links, err := encodeList("links", event.Links)
+	if err != nil {
+		return err
+	}
+	tags, err := encodeList("tags", event.Tags)
+	if err != nil {
+		return err
+	}
 
 	query := `
-		INSERT INTO events (id, title, description, start_time, end_time, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO {prefix}events (id, title, description, start_time, end_time, timezone, created_at, created_by, updated_at, status, metadata, recurrence, links, meeting_url, priority, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := db.DB.ExecContext(ctx, query,
+	event.TimeZone = models.ZoneOffset(event.StartTime)
+	_, err = exec.ExecContext(ctx, db.sql(query),
 		event.ID.String(),
 		event.Title,
 		event.Description,
-		event.StartTime.Format(time.RFC3339),
-		event.EndTime.Format(time.RFC3339),
-		event.CreatedAt.Format(time.RFC3339),
+		formatTime(event.StartTime),
+		formatTime(event.EndTime),
+		event.TimeZone,
+		formatTime(event.CreatedAt),
+		event.CreatedBy,
+		formatTime(event.UpdatedAt),
+		event.Status,
+		metadata,
+		recurrence,
+		links,
+		event.MeetingURL,
+		event.Priority,
+		tags,
 	)
 
 	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return ErrEventExists
+		}
 		return fmt.Errorf("failed to insert event: %w", err)
 	}
+	if err := db.writeTags(ctx, exec, event.ID, event.Tags); err != nil {
+		return err
+	}
+	return db.recordAudit(ctx, exec, event.ID, models.AuditCreate, nil, event, event.CreatedAt)
+}
 
-	log.Printf("Event inserted successfully with ID: %s", event.ID)
-	return nil
+// eventColumns lists the events table columns in the order scanEvent expects them
+const eventColumns = `id, title, description, start_time, end_time, timezone, created_at, created_by, updated_at, status, deleted_at, metadata, recurrence, links, meeting_url, priority, tags`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-// GetEventByID retrieves an event by its ID
-func (db *Database) GetEventByID(ctx context.Context, id uuid.UUID) (*models.Event, error) {
-	query := `
-		SELECT id, title, description, start_time, end_time, created_at
-		FROM events
-		WHERE id = ?
-	`
+// encodeMetadata returns the stored form of event metadata, NULL when there is none
+func encodeMetadata(metadata map[string]interface{}) (interface{}, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+// encodeRecurrence returns the stored form of an event recurrence, NULL when there is none
+func encodeRecurrence(recurrence *models.Recurrence) (interface{}, error) {
+	if recurrence == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recurrence: %w", err)
+	}
+	return string(data), nil
+}
+
+// encodeList returns the stored form of a list of strings such as links or tags,
+// a JSON array or NULL when the list is empty
+func encodeList(name string, list []string) (interface{}, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return string(data), nil
+}
 
+// scanEvent scans a row selected with eventColumns into an event
+func scanEvent(row rowScanner) (*models.Event, error) {
 	var event models.Event
 	var idStr string
-	var startTimeStr, endTimeStr, createdAtStr string
+	var startTimeStr, endTimeStr, createdAtStr, updatedAtStr string
+	var timezoneStr, deletedAtStr, metadataStr, recurrenceStr, linksStr, tagsStr sql.NullString
 
-	err := db.DB.QueryRowContext(ctx, query, id.String()).Scan(
+	err := row.Scan(
 		&idStr,
 		&event.Title,
 		&event.Description,
 		&startTimeStr,
 		&endTimeStr,
+		&timezoneStr,
 		&createdAtStr,
+		&event.CreatedBy,
+		&updatedAtStr,
+		&event.Status,
+		&deletedAtStr,
+		&metadataStr,
+		&recurrenceStr,
+		&linksStr,
+		&event.MeetingURL,
+		&event.Priority,
+		&tagsStr,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("event not found")
-		}
-		return nil, fmt.Errorf("failed to get event: %w", err)
+		return nil, err
 	}
 
 	// Parse UUID
@@ -151,79 +608,78 @@ func (db *Database) GetEventByID(ctx context.Context, id uuid.UUID) (*models.Eve
 	}
 
 	// Parse timestamps
-	event.StartTime, err = time.Parse(time.RFC3339, startTimeStr)
+	event.StartTime, err = time.Parse(time.RFC3339Nano, startTimeStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse start_time: %w", err)
 	}
 
-	event.EndTime, err = time.Parse(time.RFC3339, endTimeStr)
+	event.EndTime, err = time.Parse(time.RFC3339Nano, endTimeStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse end_time: %w", err)
 	}
 
-	event.CreatedAt, err = time.Parse(time.RFC3339, createdAtStr)
+	event.TimeZone = timezoneStr.String
+	if event.StartTime, err = inZone(event.StartTime, event.TimeZone); err != nil {
+		return nil, err
+	}
+	if event.EndTime, err = inZone(event.EndTime, event.TimeZone); err != nil {
+		return nil, err
+	}
+
+	event.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAtStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse created_at: %w", err)
 	}
 
-	return &event, nil
-}
-
-// GetAllEvents retrieves all events from the database
-func (db *Database) GetAllEvents(ctx context.Context) ([]*models.Event, error) {
-	query := `
-		SELECT id, title, description, start_time, end_time, created_at
-		FROM events
-		ORDER BY start_time ASC
-	`
-
-	rows, err := db.DB.QueryContext(ctx, query)
+	event.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAtStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query events: %w", err)
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
 	}
-	defer rows.Close()
 
-	var events []*models.Event
-	for rows.Next() {
-		var event models.Event
-		var idStr string
-		var startTimeStr, endTimeStr, createdAtStr string
-
-		err := rows.Scan(
-			&idStr,
-			&event.Title,
-			&event.Description,
-			&startTimeStr,
-			&endTimeStr,
-			&createdAtStr,
-		)
+	if deletedAtStr.Valid {
+		deletedAt, err := time.Parse(time.RFC3339Nano, deletedAtStr.String)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
+			return nil, fmt.Errorf("failed to parse deleted_at: %w", err)
 		}
+		event.DeletedAt = &deletedAt
+	}
 
-		// Parse UUID
-		event.ID, err = uuid.Parse(idStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse UUID: %w", err)
+	if metadataStr.Valid {
+		if err := json.Unmarshal([]byte(metadataStr.String), &event.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata: %w", err)
 		}
+	}
 
-		// Parse timestamps
-		event.StartTime, err = time.Parse(time.RFC3339, startTimeStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+	if recurrenceStr.Valid {
+		if err := json.Unmarshal([]byte(recurrenceStr.String), &event.Recurrence); err != nil {
+			return nil, fmt.Errorf("failed to parse recurrence: %w", err)
 		}
+	}
 
-		event.EndTime, err = time.Parse(time.RFC3339, endTimeStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse end_time: %w", err)
+	if linksStr.Valid {
+		if err := json.Unmarshal([]byte(linksStr.String), &event.Links); err != nil {
+			return nil, fmt.Errorf("failed to parse links: %w", err)
 		}
+	}
 
-		event.CreatedAt, err = time.Parse(time.RFC3339, createdAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	if tagsStr.Valid {
+		if err := json.Unmarshal([]byte(tagsStr.String), &event.Tags); err != nil {
+			return nil, fmt.Errorf("failed to parse tags: %w", err)
 		}
+	}
 
-		events = append(events, &event)
+	return &event, nil
+}
+
+// scanEvents scans all remaining rows into events
+func scanEvents(rows *sql.Rows) ([]*models.Event, error) {
+	var events []*models.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -233,81 +689,1121 @@ func (db *Database) GetAllEvents(ctx context.Context) ([]*models.Event, error) {
 	return events, nil
 }
 
-// UpdateEvent updates an existing event
-func (db *Database) UpdateEvent(ctx context.Context, event *models.Event) error {
+// GetEventByID retrieves an event by its ID
+func (db *Database) GetEventByID(ctx context.Context, id uuid.UUID) (*models.Event, error) {
+	defer db.observe(ctx, "GetEventByID", eventIDAttr(id))()
+
 	query := `
-		UPDATE events
-		SET title = ?, description = ?, start_time = ?, end_time = ?
-		WHERE id = ?
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
-	result, err := db.DB.ExecContext(ctx, query,
-		event.Title,
-		event.Description,
-		event.StartTime.Format(time.RFC3339),
-		event.EndTime.Format(time.RFC3339),
-		event.ID.String(),
-	)
+	event, err := scanEvent(db.Reader.QueryRowContext(ctx, db.sql(query), id.String()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetAllEvents retrieves all events matching the filter from the database
+// Without a limit at most maxResults events are returned
+func (db *Database) GetAllEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error) {
+	defer db.observe(ctx, "GetAllEvents")()
 
+	where, args := filterClause(filter)
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		` + where + `
+		` + orderClause(filter) + `
+	`
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	} else {
+		// Fetch one more than the cap to tell whether it was hit
+		query += " LIMIT ?"
+		args = append(args, maxResults+1)
+	}
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query), args...)
 	if err != nil {
-		return fmt.Errorf("failed to update event: %w", err)
+		return nil, fmt.Errorf("failed to query events: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	events, err := scanEvents(rows)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("event not found")
+	if len(events) > maxResults {
+		db.logger.Warn("GetAllEvents hit the result cap, the rest were dropped; use pagination", "cap", maxResults)
+		events = events[:maxResults]
 	}
+	return events, nil
+}
 
-	log.Printf("Event updated successfully with ID: %s", event.ID)
-	return nil
+// CountEvents counts the events matching the filter, ignoring its limit and offset
+func (db *Database) CountEvents(ctx context.Context, filter models.EventFilter) (int, error) {
+	defer db.observe(ctx, "CountEvents")()
+
+	where, args := filterClause(filter)
+
+	query := `
+		SELECT COUNT(*)
+		FROM {prefix}events
+		` + where + `
+	`
+
+	var count int
+	if err := db.Reader.QueryRowContext(ctx, db.sql(query), args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+
+	return count, nil
 }
 
-// DeleteEvent deletes an event by ID
-func (db *Database) DeleteEvent(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM events WHERE id = ?`
+// CountEventsByOwner counts the active events created by owner: those that are neither
+// deleted nor cancelled
+func (db *Database) CountEventsByOwner(ctx context.Context, owner string) (int, error) {
+	defer db.observe(ctx, "CountEventsByOwner")()
+	return db.countEventsByOwner(ctx, db.Reader, owner)
+}
 
-	result, err := db.DB.ExecContext(ctx, query, id.String())
-	if err != nil {
-		return fmt.Errorf("failed to delete event: %w", err)
+// countEventsByOwner implements CountEventsByOwner using the given queryer
+func (db *Database) countEventsByOwner(ctx context.Context, q rowQueryer, owner string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM {prefix}events
+		WHERE created_by = ? AND status != ? AND deleted_at IS NULL
+	`
+
+	var count int
+	if err := q.QueryRowContext(ctx, db.sql(query), owner, models.StatusCancelled).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count events by owner: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return count, nil
+}
+
+// GetEventStats computes the summary of the events that aren't deleted, counting those
+// created since the given time as recent
+func (db *Database) GetEventStats(ctx context.Context, since time.Time) (*models.EventStats, error) {
+	defer db.observe(ctx, "GetEventStats")()
+
+	query := `
+		SELECT COUNT(*), MIN(start_time), MAX(start_time), AVG(` + durationMillis + `),
+			COUNT(CASE WHEN created_at >= ? THEN 1 END)
+		FROM {prefix}events
+		WHERE deleted_at IS NULL
+	`
+
+	stats := &models.EventStats{ByStatus: map[string]int{
+		models.StatusConfirmed: 0,
+		models.StatusTentative: 0,
+		models.StatusCancelled: 0,
+	}}
+	var earliest, latest sql.NullString
+	var average sql.NullFloat64
+	err := db.Reader.QueryRowContext(ctx, db.sql(query), formatTime(since)).
+		Scan(&stats.Total, &earliest, &latest, &average, &stats.CreatedLast24h)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to compute event stats: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("event not found")
+	if earliest.Valid && latest.Valid {
+		earliestTime, err := time.Parse(time.RFC3339Nano, earliest.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+		}
+		latestTime, err := time.Parse(time.RFC3339Nano, latest.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+		}
+		stats.EarliestStartTime, stats.LatestStartTime = &earliestTime, &latestTime
+	}
+	if average.Valid {
+		stats.AverageDurationMS = int64(math.Round(average.Float64))
 	}
 
-	log.Printf("Event deleted successfully with ID: %s", id)
-	return nil
-}
+	rows, err := db.Reader.QueryContext(ctx, db.sql(`
+		SELECT status, COUNT(*)
+		FROM {prefix}events
+		WHERE deleted_at IS NULL
+		GROUP BY status
+	`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count events by status: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan status count: %w", err)
+		}
+		stats.ByStatus[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetEventTitles retrieves the ID, title and start time of every event ordered by start time
+// Only those columns are read so descriptions and metadata aren't loaded
+func (db *Database) GetEventTitles(ctx context.Context) ([]models.EventTitle, error) {
+	defer db.observe(ctx, "GetEventTitles")()
+
+	query := `
+		SELECT id, title, start_time, timezone
+		FROM {prefix}events
+		WHERE deleted_at IS NULL
+		ORDER BY start_time ASC, id ASC
+	`
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event titles: %w", err)
+	}
+	defer rows.Close()
+
+	var titles []models.EventTitle
+	for rows.Next() {
+		var title models.EventTitle
+		var idStr, startTimeStr string
+		var timezoneStr sql.NullString
+		if err := rows.Scan(&idStr, &title.Title, &startTimeStr, &timezoneStr); err != nil {
+			return nil, fmt.Errorf("failed to scan event title: %w", err)
+		}
+
+		title.ID, err = uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UUID: %w", err)
+		}
+		title.StartTime, err = time.Parse(time.RFC3339Nano, startTimeStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse start_time: %w", err)
+		}
+		if title.StartTime, err = inZone(title.StartTime, timezoneStr.String); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event titles: %w", err)
+	}
+
+	return titles, nil
+}
+
+// GetEventsVersion returns the number of events matching the filter, ignoring its limit
+// and offset, and their latest updated_at (zero when there are none)
+// Together they change whenever an event matching the filter is created, updated or deleted
+func (db *Database) GetEventsVersion(ctx context.Context, filter models.EventFilter) (int, time.Time, error) {
+	defer db.observe(ctx, "GetEventsVersion")()
+
+	where, args := filterClause(filter)
+
+	query := `
+		SELECT COUNT(*), MAX(updated_at)
+		FROM {prefix}events
+		` + where + `
+	`
+
+	var count int
+	var maxUpdatedStr sql.NullString
+	if err := db.Reader.QueryRowContext(ctx, db.sql(query), args...).Scan(&count, &maxUpdatedStr); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get events version: %w", err)
+	}
+
+	var maxUpdated time.Time
+	if maxUpdatedStr.Valid {
+		var err error
+		maxUpdated, err = time.Parse(time.RFC3339Nano, maxUpdatedStr.String)
+		if err != nil {
+			return 0, time.Time{}, fmt.Errorf("failed to parse updated_at: %w", err)
+		}
+	}
+
+	return count, maxUpdated, nil
+}
+
+// StreamEvents calls fn for every event ordered by creation time
+// Rows are read one at a time from the cursor so memory use doesn't grow with the table
+func (db *Database) StreamEvents(ctx context.Context, fn func(*models.Event) error) error {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE deleted_at IS NULL
+		ORDER BY created_at ASC, id ASC
+	`
+
+	// Only observe the query itself, fn may be as slow as the client reading the export
+	end := db.observe(ctx, "StreamEvents")
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query))
+	end()
+	if err != nil {
+		return fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan event: %w", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating events: %w", err)
+	}
+	return nil
+}
+
+// GetChangesSince retrieves events created, updated or soft-deleted after since,
+// ordered by updated_at, including soft-deleted ones
+func (db *Database) GetChangesSince(ctx context.Context, since time.Time) ([]*models.Event, error) {
+	defer db.observe(ctx, "GetChangesSince")()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE updated_at > ?
+		ORDER BY updated_at ASC, id ASC
+	`
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query), formatTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// durationMillis is the SQL expression computing the duration of an event in milliseconds
+const durationMillis = "CAST(ROUND((julianday(end_time) - julianday(start_time)) * 86400000) AS INTEGER)"
+
+// filterClause builds the WHERE clause and arguments selecting events matching the filter
+func filterClause(filter models.EventFilter) (string, []interface{}) {
+	// Soft-deleted events are never listed
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if filter.Owner != "" {
+		conditions = append(conditions, "created_by = ?")
+		args = append(args, filter.Owner)
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, formatTime(filter.CreatedFrom))
+	}
+
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, formatTime(filter.CreatedTo))
+	}
+
+	// Durations compare in whole milliseconds, julianday's precision, so an event lasting
+	// exactly the bound isn't lost to floating point rounding
+	if filter.MinDuration != 0 {
+		conditions = append(conditions, durationMillis+" >= ?")
+		args = append(args, filter.MinDuration.Milliseconds())
+	}
+
+	if filter.MaxDuration != 0 {
+		conditions = append(conditions, durationMillis+" <= ?")
+		args = append(args, filter.MaxDuration.Milliseconds())
+	}
+
+	// Sort the keys so the same filter always builds the same query
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// Values are compared as text so numbers match their query string form
+		conditions = append(conditions, "CAST(json_extract(metadata, ?) AS TEXT) = ?")
+		args = append(args, "$."+key, filter.Metadata[key])
+	}
+
+	for _, tag := range filter.Tags {
+		conditions = append(conditions, "id IN (SELECT event_id FROM {prefix}event_tags WHERE tag = ?)")
+		args = append(args, tag)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// orderClause builds the ORDER BY clause for the filter
+// Sort fields are named like their columns; anything else falls back to start_time
+func orderClause(filter models.EventFilter) string {
+	column := models.SortStartTime
+	if filter.Sort.Valid() {
+		column = filter.Sort
+	}
+
+	direction := "ASC"
+	if filter.Order == models.Desc {
+		direction = "DESC"
+	}
+
+	// Tie-break on id so the order is stable
+	return "ORDER BY " + string(column) + " " + direction + ", id " + direction
+}
+
+// GetEventsByIDs retrieves the events with the given IDs
+// IDs that don't match an event are simply absent from the result
+func (db *Database) GetEventsByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Event, error) {
+	defer db.observe(ctx, "GetEventsByIDs")()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id.String()
+	}
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE id IN (` + placeholders + `) AND deleted_at IS NULL
+		ORDER BY start_time ASC, id ASC
+	`
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// ExistingEventIDs returns which of ids are taken by a stored event, soft-deleted ones included
+func (db *Database) ExistingEventIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	defer db.observe(ctx, "ExistingEventIDs")()
+
+	existing := make(map[uuid.UUID]bool)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id.String()
+	}
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(`SELECT id FROM {prefix}events WHERE id IN (`+placeholders+`)`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event IDs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idStr string
+		if err := rows.Scan(&idStr); err != nil {
+			return nil, fmt.Errorf("failed to scan event ID: %w", err)
+		}
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse event ID: %w", err)
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+// GetEventsInRange retrieves events starting within [from, to) ordered by start time,
+// plus every recurring event whose series starts before to
+// Recurring events are returned once, callers expand them with Event.Occurrences
+func (db *Database) GetEventsInRange(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+	defer db.observe(ctx, "GetEventsInRange")()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE ((start_time >= ? AND start_time < ?) OR (recurrence IS NOT NULL AND start_time < ?))
+			AND deleted_at IS NULL
+		ORDER BY start_time ASC
+	`
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query),
+		formatTime(from),
+		formatTime(to),
+		formatTime(to),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// likeEscaper escapes the LIKE wildcards so a value only matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetNextEventByTitle retrieves the earliest event starting at or after now whose title
+// matches case-insensitively, skipping cancelled events
+// Returns ErrEventNotFound when there is no such upcoming event
+func (db *Database) GetNextEventByTitle(ctx context.Context, title string, now time.Time) (*models.Event, error) {
+	defer db.observe(ctx, "GetNextEventByTitle")()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE title LIKE ? ESCAPE '\' AND start_time >= ? AND status != ? AND deleted_at IS NULL
+		ORDER BY start_time ASC
+		LIMIT 1
+	`
+
+	row := db.Reader.QueryRowContext(ctx, db.sql(query),
+		likeEscaper.Replace(title),
+		formatTime(now),
+		models.StatusCancelled,
+	)
+	event, err := scanEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get next event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetAdjacentEvents retrieves the events right before and after event by start time, nil
+// when event is the first or last one. Events starting at the same time are ordered by ID,
+// as in listings, so each of them is visited once.
+func (db *Database) GetAdjacentEvents(ctx context.Context, event *models.Event) (*models.Event, *models.Event, error) {
+	defer db.observe(ctx, "GetAdjacentEvents", eventIDAttr(event.ID))()
+
+	previousQuery := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE (start_time < ? OR (start_time = ? AND id < ?)) AND deleted_at IS NULL
+		ORDER BY start_time DESC, id DESC
+		LIMIT 1
+	`
+	nextQuery := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE (start_time > ? OR (start_time = ? AND id > ?)) AND deleted_at IS NULL
+		ORDER BY start_time ASC, id ASC
+		LIMIT 1
+	`
+
+	adjacent := make([]*models.Event, 2)
+	for i, query := range []string{previousQuery, nextQuery} {
+		startTime := formatTime(event.StartTime)
+		row := db.Reader.QueryRowContext(ctx, db.sql(query), startTime, startTime, event.ID.String())
+
+		other, err := scanEvent(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get adjacent event: %w", err)
+		}
+		adjacent[i] = other
+	}
+
+	return adjacent[0], adjacent[1], nil
+}
+
+// RestoreEvents upserts events keeping their IDs and creation times, in a single transaction
+// Inserted events, and stored ones the restore changes or brings back from deletion, are
+// updated at the restore time so delta sync reports them. Stored events already matching
+// keep their updated_at, so re-running a restore with the same events leaves the table
+// unchanged.
+func (db *Database) RestoreEvents(ctx context.Context, events []*models.Event) error {
+	defer db.observe(ctx, "RestoreEvents")()
+
+	query := `
+		INSERT INTO {prefix}events (id, title, description, start_time, end_time, timezone, created_at, created_by, updated_at, status, metadata, recurrence, links, meeting_url, priority, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			start_time = excluded.start_time,
+			end_time = excluded.end_time,
+			timezone = excluded.timezone,
+			created_at = excluded.created_at,
+			created_by = excluded.created_by,
+			updated_at = CASE
+				WHEN {prefix}events.deleted_at IS NULL
+					AND {prefix}events.title IS excluded.title
+					AND {prefix}events.description IS excluded.description
+					AND {prefix}events.start_time IS excluded.start_time
+					AND {prefix}events.end_time IS excluded.end_time
+					AND {prefix}events.timezone IS excluded.timezone
+					AND {prefix}events.created_at IS excluded.created_at
+					AND {prefix}events.created_by IS excluded.created_by
+					AND {prefix}events.status IS excluded.status
+					AND {prefix}events.metadata IS excluded.metadata
+					AND {prefix}events.recurrence IS excluded.recurrence
+					AND {prefix}events.links IS excluded.links
+					AND {prefix}events.meeting_url IS excluded.meeting_url
+					AND {prefix}events.priority IS excluded.priority
+					AND {prefix}events.tags IS excluded.tags
+				THEN {prefix}events.updated_at
+				ELSE excluded.updated_at
+			END,
+			status = excluded.status,
+			metadata = excluded.metadata,
+			recurrence = excluded.recurrence,
+			links = excluded.links,
+			meeting_url = excluded.meeting_url,
+			priority = excluded.priority,
+			tags = excluded.tags,
+			deleted_at = NULL
+	`
+
+	now := time.Now()
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			return db.restoreEvents(ctx, tx, query, events, now)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Info("Restored events", "count", len(events))
+	return nil
+}
+
+// restoreEvents upserts events using the given transaction, as updated at now
+func (db *Database) restoreEvents(ctx context.Context, tx *sql.Tx, query string, events []*models.Event, now time.Time) error {
+	stmt, err := tx.PrepareContext(ctx, db.sql(query))
+	if err != nil {
+		return fmt.Errorf("failed to prepare restore: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		old, err := db.currentEvent(ctx, tx, event.ID)
+		if err != nil && !errors.Is(err, ErrEventNotFound) {
+			return err
+		}
+
+		metadata, err := encodeMetadata(event.Metadata)
+		if err != nil {
+			return err
+		}
+		recurrence, err := encodeRecurrence(event.Recurrence)
+		if err != nil {
+			return err
+		}
+		links, err := encodeList("links", event.Links)
+		if err != nil {
+			return err
+		}
+		tags, err := encodeList("tags", event.Tags)
+		if err != nil {
+			return err
+		}
+
+		event.TimeZone = models.ZoneOffset(event.StartTime)
+		_, err = stmt.ExecContext(ctx,
+			event.ID.String(),
+			event.Title,
+			event.Description,
+			formatTime(event.StartTime),
+			formatTime(event.EndTime),
+			event.TimeZone,
+			formatTime(event.CreatedAt),
+			event.CreatedBy,
+			formatTime(now),
+			event.Status,
+			metadata,
+			recurrence,
+			links,
+			event.MeetingURL,
+			event.Priority,
+			tags,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore event %s: %w", event.ID, err)
+		}
+		if err := db.writeTags(ctx, tx, event.ID, event.Tags); err != nil {
+			return err
+		}
+
+		if err := db.auditChange(ctx, tx, event.ID, old, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditChange records the audit entry of a change to the stored event with the given ID,
+// reading its new state inside tx; old is nil when the change created the event
+func (db *Database) auditChange(ctx context.Context, tx *sql.Tx, id uuid.UUID, old *models.Event, at time.Time) error {
+	new, err := db.currentEvent(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	operation := models.AuditUpdate
+	if old == nil {
+		operation = models.AuditCreate
+	}
+	return db.recordAudit(ctx, tx, id, operation, old, new, at)
+}
+
+// CountEventsInRange counts events overlapping the time range [from, to), skipping the exclude events
+// Cancelled events don't occupy their slot and aren't counted, as in FindOverlappingEvents
+func (db *Database) CountEventsInRange(ctx context.Context, from, to time.Time, exclude ...uuid.UUID) (int, error) {
+	defer db.observe(ctx, "CountEventsInRange")()
+	return db.countEventsInRange(ctx, db.Reader, from, to, exclude)
+}
+
+// countEventsInRange implements CountEventsInRange using the given queryer
+func (db *Database) countEventsInRange(ctx context.Context, q rowQueryer, from, to time.Time, exclude []uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM {prefix}events
+		WHERE start_time < ? AND end_time > ? AND status != ? AND deleted_at IS NULL
+	`
+	args := []interface{}{formatTime(to), formatTime(from), models.StatusCancelled}
+
+	clause, excludeArgs := excludeClause(exclude)
+	query += clause
+	args = append(args, excludeArgs...)
+
+	var count int
+	err := q.QueryRowContext(ctx, db.sql(query), args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+
+	return count, nil
+}
+
+// excludeClause returns the condition leaving the exclude events out of a query, and its
+// arguments; both are empty when there's nothing to exclude
+func excludeClause(exclude []uuid.UUID) (string, []interface{}) {
+	if len(exclude) == 0 {
+		return "", nil
+	}
+
+	placeholders := make([]string, len(exclude))
+	args := make([]interface{}, len(exclude))
+	for i, id := range exclude {
+		placeholders[i] = "?"
+		args[i] = id.String()
+	}
+	return " AND id NOT IN (" + strings.Join(placeholders, ", ") + ")", args
+}
+
+// ShiftEvents stores the new start and end times, and the recurrence moved with them, of
+// several events in a single transaction
+// Returns ErrEventNotFound, leaving every event untouched, if any of them no longer exists
+func (db *Database) ShiftEvents(ctx context.Context, events []*models.Event) error {
+	defer db.observe(ctx, "ShiftEvents")()
+
+	query := `
+		UPDATE {prefix}events
+		SET start_time = ?, end_time = ?, timezone = ?, recurrence = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	updatedAt := time.Now()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			for _, event := range events {
+				old, err := db.currentEvent(ctx, tx, event.ID)
+				if err != nil {
+					return err
+				}
+
+				recurrence, err := encodeRecurrence(event.Recurrence)
+				if err != nil {
+					return err
+				}
+
+				event.TimeZone = models.ZoneOffset(event.StartTime)
+				result, err := tx.ExecContext(ctx, db.sql(query),
+					formatTime(event.StartTime),
+					formatTime(event.EndTime),
+					event.TimeZone,
+					recurrence,
+					formatTime(updatedAt),
+					event.ID.String(),
+				)
+				if err != nil {
+					return fmt.Errorf("failed to shift event %s: %w", event.ID, err)
+				}
+
+				rowsAffected, err := result.RowsAffected()
+				if err != nil {
+					return fmt.Errorf("failed to get rows affected: %w", err)
+				}
+				if rowsAffected == 0 {
+					return ErrEventNotFound
+				}
+
+				if err := db.auditChange(ctx, tx, event.ID, old, updatedAt); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		event.UpdatedAt = updatedAt
+	}
+
+	db.logger.Info("Shifted events", "count", len(events))
+	return nil
+}
+
+// RescheduleEvent stores new start and end times for an event, leaving its other columns alone
+// When precondition isn't nil it is checked against the stored event in the same
+// transaction, and ErrPreconditionFailed is returned, changing nothing, if it doesn't hold.
+func (db *Database) RescheduleEvent(ctx context.Context, id uuid.UUID, start, end time.Time, precondition func(*models.Event) bool) error {
+	defer db.observe(ctx, "RescheduleEvent", eventIDAttr(id))()
+
+	query := `
+		UPDATE {prefix}events
+		SET start_time = ?, end_time = ?, timezone = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	updatedAt := time.Now()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			old, err := db.currentEvent(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if old.DeletedAt != nil {
+				return ErrEventNotFound
+			}
+			if precondition != nil && !precondition(old) {
+				return ErrPreconditionFailed
+			}
+
+			result, err := tx.ExecContext(ctx, db.sql(query),
+				formatTime(start),
+				formatTime(end),
+				models.ZoneOffset(start),
+				formatTime(updatedAt),
+				id.String(),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to reschedule event: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return ErrEventNotFound
+			}
+
+			return db.auditChange(ctx, tx, id, old, updatedAt)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Debug("Event rescheduled successfully", "id", id)
+	return nil
+}
+
+// FindOverlappingEvents retrieves the events overlapping [start, end) ordered by start time
+// Cancelled events don't occupy their slot and are skipped, and so are the exclude events
+func (db *Database) FindOverlappingEvents(ctx context.Context, start, end time.Time, exclude ...uuid.UUID) ([]*models.Event, error) {
+	defer db.observe(ctx, "FindOverlappingEvents")()
+
+	clause, excludeArgs := excludeClause(exclude)
+	query := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE start_time < ? AND end_time > ? AND status != ? AND deleted_at IS NULL` + clause + `
+		ORDER BY start_time ASC, id ASC
+	`
+	args := append([]interface{}{formatTime(end), formatTime(start), models.StatusCancelled}, excludeArgs...)
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overlapping events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// UpdateEvent updates an existing event
+// When precondition isn't nil it is checked against the stored event in the same
+// transaction, and ErrPreconditionFailed is returned, changing nothing, if it doesn't hold.
+func (db *Database) UpdateEvent(ctx context.Context, event *models.Event, precondition func(*models.Event) bool) error {
+	defer db.observe(ctx, "UpdateEvent", eventIDAttr(event.ID))()
+
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			return db.updateEvent(ctx, tx, event, precondition)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Debug("Event updated successfully", "id", event.ID)
+	return nil
+}
+
+// updateEvent stores the new content of an event using the given transaction, checking
+// precondition against the stored event first when it isn't nil
+func (db *Database) updateEvent(ctx context.Context, tx *sql.Tx, event *models.Event, precondition func(*models.Event) bool) error {
+	query := `
+		UPDATE {prefix}events
+		SET title = ?, description = ?, start_time = ?, end_time = ?, timezone = ?, updated_at = ?, status = ?, metadata = ?, recurrence = ?, links = ?, meeting_url = ?, priority = ?, tags = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	metadata, err := encodeMetadata(event.Metadata)
+	if err != nil {
+		return err
+	}
+	recurrence, err := encodeRecurrence(event.Recurrence)
+	if err != nil {
+		return err
+	}
+	links, err := encodeList("links", event.Links)
+	if err != nil {
+		return err
+	}
+	tags, err := encodeList("tags", event.Tags)
+	if err != nil {
+		return err
+	}
+
+	old, err := db.currentEvent(ctx, tx, event.ID)
+	if err != nil {
+		return err
+	}
+	if old.DeletedAt != nil {
+		return ErrEventNotFound
+	}
+	if precondition != nil && !precondition(old) {
+		return ErrPreconditionFailed
+	}
+
+	event.UpdatedAt = time.Now()
+	event.TimeZone = models.ZoneOffset(event.StartTime)
+
+	result, err := tx.ExecContext(ctx, db.sql(query),
+		event.Title,
+		event.Description,
+		formatTime(event.StartTime),
+		formatTime(event.EndTime),
+		event.TimeZone,
+		formatTime(event.UpdatedAt),
+		event.Status,
+		metadata,
+		recurrence,
+		links,
+		event.MeetingURL,
+		event.Priority,
+		tags,
+		event.ID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrEventNotFound
+	}
+
+	if err := db.writeTags(ctx, tx, event.ID, event.Tags); err != nil {
+		return err
+	}
+	return db.auditChange(ctx, tx, event.ID, old, event.UpdatedAt)
+}
+
+// DeleteEvent soft-deletes an event by ID
+// The row is kept with deleted_at set so sync clients can learn about the deletion.
+// When precondition isn't nil it is checked against the stored event in the same
+// transaction, and ErrPreconditionFailed is returned, deleting nothing, if it doesn't hold.
+func (db *Database) DeleteEvent(ctx context.Context, id uuid.UUID, precondition func(*models.Event) bool) error {
+	defer db.observe(ctx, "DeleteEvent", eventIDAttr(id))()
+
+	query := `
+		UPDATE {prefix}events
+		SET deleted_at = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	now := time.Now()
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			old, err := db.currentEvent(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if old.DeletedAt != nil {
+				return ErrEventNotFound
+			}
+			if precondition != nil && !precondition(old) {
+				return ErrPreconditionFailed
+			}
+
+			result, err := tx.ExecContext(ctx, db.sql(query), formatTime(now), formatTime(now), id.String())
+			if err != nil {
+				return fmt.Errorf("failed to delete event: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return ErrEventNotFound
+			}
+
+			return db.recordAudit(ctx, tx, id, models.AuditDelete, old, nil, now)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	db.logger.Debug("Event deleted successfully", "id", id)
+	return nil
+}
+
+// PurgeEventsEndedBefore permanently removes events that ended before cutoff,
+// including soft-deleted ones, and returns how many were removed
+// A recurring event is only removed once its last occurrence ended before cutoff, so a
+// series that started long ago but still runs is kept.
+func (db *Database) PurgeEventsEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	defer db.observe(ctx, "PurgeEventsEndedBefore")()
+
+	seriesQuery := `
+		SELECT ` + eventColumns + `
+		FROM {prefix}events
+		WHERE end_time < ? AND recurrence IS NOT NULL
+	`
+
+	var removed int64
+	err := db.retryBusy(ctx, func() error {
+		return db.withTx(ctx, func(tx *sql.Tx) error {
+			rows, err := tx.QueryContext(ctx, db.sql(seriesQuery), formatTime(cutoff))
+			if err != nil {
+				return fmt.Errorf("failed to find recurring events: %w", err)
+			}
+			series, err := scanEvents(rows)
+			rows.Close()
+			if err != nil {
+				return err
+			}
+
+			var running []uuid.UUID
+			for _, event := range series {
+				if lastEnd, ok := event.LastEnd(); !ok || !lastEnd.Before(cutoff) {
+					running = append(running, event.ID)
+				}
+			}
+			exclude, excludeArgs := excludeClause(running)
+			args := append([]interface{}{formatTime(cutoff)}, excludeArgs...)
+
+			tagsQuery := `
+				DELETE FROM {prefix}event_tags
+				WHERE event_id IN (SELECT id FROM {prefix}events WHERE end_time < ?` + exclude + `)
+			`
+			if _, err := tx.ExecContext(ctx, db.sql(tagsQuery), args...); err != nil {
+				return fmt.Errorf("failed to purge event tags: %w", err)
+			}
+
+			query := `
+				DELETE FROM {prefix}events
+				WHERE end_time < ?` + exclude + `
+			`
+			result, err := tx.ExecContext(ctx, db.sql(query), args...)
+			if err != nil {
+				return fmt.Errorf("failed to purge events: %w", err)
+			}
+
+			removed, err = result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
 
 // Example usage
 func main() {
 	ctx := context.Background()
+	logger := slog.Default()
 
-	// Get database path from environment variable
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "./events.db"
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
 	}
 
 	// Create database connection
-	db, err := NewDatabase(ctx, dbPath)
+	db, err := NewDatabase(ctx, cfg, logger)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logger.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
 	}
 	defer db.Close()
 
 	// Create table
 	if err := db.CreateTable(ctx); err != nil {
-		log.Fatalf("Failed to create table: %v", err)
+		logger.Error("Failed to create table", "error", err)
+		os.Exit(1)
 	}
 
 	// Example: Insert a new event
@@ -319,18 +1815,18 @@ func main() {
 		EndTime:     time.Now().Add(25 * time.Hour),
 	}
 
-	if err := db.InsertEvent(ctx, newEvent); err != nil {
-		log.Printf("Failed to insert event: %v", err)
+	if err := db.InsertEvent(ctx, newEvent, nil); err != nil {
+		logger.Error("Failed to insert event", "error", err)
 	}
 
 	// Example: Get all events
-	events, err := db.GetAllEvents(ctx)
+	events, err := db.GetAllEvents(ctx, models.EventFilter{})
 	if err != nil {
-		log.Printf("Failed to get events: %v", err)
+		logger.Error("Failed to get events", "error", err)
 	} else {
-		log.Printf("Found %d events", len(events))
+		logger.Info("Found events", "count", len(events))
 		for _, event := range events {
-			log.Printf("Event: %s - %s", event.ID, event.Title)
+			logger.Info("Event", "id", event.ID, "title", event.Title)
 		}
 	}
 }
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"challenge/config"
+	"challenge/models"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestDatabase opens a migrated database in a temporary directory
+func newTestDatabase(t testing.TB) *Database {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.DBPath = filepath.Join(t.TempDir(), "events.db")
+
+	db, err := NewDatabase(context.Background(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(db.Close)
+	if err := db.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	return db
+}
+
+// newTestEvent returns an unsaved one hour event starting at start
+func newTestEvent(db *Database, title string, start time.Time) *models.Event {
+	return &models.Event{
+		ID:        db.NewID(),
+		Title:     title,
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Status:    models.StatusConfirmed,
+	}
+}
+
+// insertTestEvent stores event, failing the test on error
+func insertTestEvent(t testing.TB, db *Database, event *models.Event) {
+	t.Helper()
+	if err := db.InsertEvent(context.Background(), event, nil); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+}
+
+func TestInsertEventKeepsMicroseconds(t *testing.T) {
+	db := newTestDatabase(t)
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 123456000, time.UTC)
+	event := newTestEvent(db, "Standup", start)
+	insertTestEvent(t, db, event)
+
+	stored, err := db.GetEventByID(context.Background(), event.ID)
+	if err != nil {
+		t.Fatalf("GetEventByID: %v", err)
+	}
+	if !stored.StartTime.Equal(start) {
+		t.Errorf("start_time = %s, want %s", stored.StartTime.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano))
+	}
+	if !stored.EndTime.Equal(event.EndTime) {
+		t.Errorf("end_time = %s, want %s", stored.EndTime.Format(time.RFC3339Nano), event.EndTime.Format(time.RFC3339Nano))
+	}
+}
+
+func TestDescriptionRoundTrip(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	empty, populated := "", "Quarterly review"
+	tests := []struct {
+		name        string
+		description *string
+	}{
+		{"nil", nil},
+		{"empty", &empty},
+		{"populated", &populated},
+	}
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newTestEvent(db, "Review", start.Add(time.Duration(i)*time.Hour))
+			event.Description = tt.description
+			insertTestEvent(t, db, event)
+
+			stored, err := db.GetEventByID(ctx, event.ID)
+			if err != nil {
+				t.Fatalf("GetEventByID: %v", err)
+			}
+			switch {
+			case tt.description == nil && stored.Description != nil:
+				t.Errorf("description = %q, want nil", *stored.Description)
+			case tt.description != nil && stored.Description == nil:
+				t.Errorf("description = nil, want %q", *tt.description)
+			case tt.description != nil && *stored.Description != *tt.description:
+				t.Errorf("description = %q, want %q", *stored.Description, *tt.description)
+			}
+		})
+	}
+}
+
+func TestEventOrderIgnoresOffsets(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	// Listed in the expected order; as text in their own offsets they would sort differently
+	starts := []string{
+		"2025-03-01T10:00:00+02:00", // 08:00 UTC
+		"2025-03-01T08:30:00Z",
+		"2025-03-01T09:00:00.5+00:00",
+		"2025-03-01T04:15:00-05:00", // 09:15 UTC
+		"2025-03-01T19:00:00+09:00", // 10:00 UTC
+	}
+	for i := len(starts) - 1; i >= 0; i-- {
+		start, err := time.Parse(time.RFC3339Nano, starts[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		insertTestEvent(t, db, newTestEvent(db, starts[i], start))
+	}
+
+	for _, order := range []models.SortOrder{models.Asc, models.Desc} {
+		events, err := db.GetAllEvents(ctx, models.EventFilter{Order: order})
+		if err != nil {
+			t.Fatalf("GetAllEvents: %v", err)
+		}
+		if len(events) != len(starts) {
+			t.Fatalf("got %d events, want %d", len(events), len(starts))
+		}
+		for i, event := range events {
+			want := starts[i]
+			if order == models.Desc {
+				want = starts[len(starts)-1-i]
+			}
+			if event.Title != want {
+				t.Errorf("%s order: event %d starts at %s, want %s", order, i, event.Title, want)
+			}
+			// The offset the event was sent with is kept for rendering
+			if got := event.StartTime.Format(time.RFC3339Nano); got != strings.Replace(event.Title, "+00:00", "Z", 1) {
+				t.Errorf("start_time = %s, want it rendered as %s", got, event.Title)
+			}
+		}
+	}
+}
+
+// BenchmarkReadsUnderWrites measures reads while another goroutine keeps inserting events,
+// through the read pool and, for comparison, through the single write connection
+func TestPurgeKeepsRunningSeries(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	daily := func(count int, until *time.Time) *models.Recurrence {
+		return &models.Recurrence{Frequency: models.FrequencyDaily, TimeZone: "UTC", Count: count, Until: until}
+	}
+	ongoingUntil := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	endedUntil := time.Date(2024, 12, 31, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		recurrence *models.Recurrence
+		kept       bool
+	}{
+		{"single event", nil, false},
+		{"endless series", daily(0, nil), true},
+		{"series until after the cutoff", daily(0, &ongoingUntil), true},
+		{"series counting past the cutoff", daily(400, nil), true},
+		{"series until before the cutoff", daily(0, &endedUntil), false},
+		{"series counted out before the cutoff", daily(10, nil), false},
+	}
+
+	ids := make([]uuid.UUID, len(tests))
+	for i, tt := range tests {
+		event := newTestEvent(db, tt.name, start)
+		event.Recurrence = tt.recurrence
+		insertTestEvent(t, db, event)
+		ids[i] = event.ID
+	}
+
+	removed, err := db.PurgeEventsEndedBefore(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PurgeEventsEndedBefore: %v", err)
+	}
+
+	want := 0
+	for i, tt := range tests {
+		if !tt.kept {
+			want++
+		}
+		_, err := db.GetEventByID(ctx, ids[i])
+		switch {
+		case tt.kept && err != nil:
+			t.Errorf("%s: GetEventByID = %v, want it kept", tt.name, err)
+		case !tt.kept && !errors.Is(err, ErrEventNotFound):
+			t.Errorf("%s: GetEventByID = %v, want it purged", tt.name, err)
+		}
+	}
+	if removed != int64(want) {
+		t.Errorf("removed %d events, want %d", removed, want)
+	}
+}
+
+func BenchmarkReadsUnderWrites(b *testing.B) {
+	for _, bench := range []struct {
+		name      string
+		viaWriter bool
+	}{
+		{"reader pool", false},
+		{"write connection", true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			db := newTestDatabase(b)
+			ctx := context.Background()
+
+			start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+			ids := make([]uuid.UUID, 200)
+			for i := range ids {
+				event := newTestEvent(db, "Seed", start.Add(time.Duration(i)*time.Hour))
+				insertTestEvent(b, db, event)
+				ids[i] = event.ID
+			}
+			if bench.viaWriter {
+				db.Reader = db.DB
+			}
+
+			// Keep the write connection busy for the whole benchmark
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					if err := db.InsertEvent(ctx, newTestEvent(db, "Write", start.Add(time.Duration(i)*time.Minute)), nil); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			}()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					if _, err := db.GetEventByID(ctx, ids[i%len(ids)]); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.StopTimer()
+
+			close(stop)
+			<-done
+		})
+	}
+}
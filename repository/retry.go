@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"challenge/config"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// busyBaseDelay is the delay before the first retry, doubled on each attempt
+const busyBaseDelay = 10 * time.Millisecond
+
+// isBusy reports whether err is a SQLITE_BUSY or SQLITE_LOCKED error
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// retryBusy runs fn, retrying with exponential backoff while it fails with a busy error
+func (db *Database) retryBusy(ctx context.Context, fn func() error) error {
+	delay := busyBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt >= db.busyRetries {
+			return err
+		}
+
+		db.logger.Warn("Database busy, retrying", "delay", delay, "attempt", attempt+1, "retries", db.busyRetries)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// maxConnectDelay caps the backoff between connection attempts
+const maxConnectDelay = 30 * time.Second
+
+// Connect opens the database with NewDatabase logging to logger, retrying with exponential backoff from
+// cfg.DBConnectInterval, up to maxConnectDelay, at most cfg.DBConnectRetries times
+// This lets the server wait for a database volume that isn't mounted yet instead of exiting.
+func Connect(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Database, error) {
+	delay := time.Duration(cfg.DBConnectInterval)
+	for attempt := 0; ; attempt++ {
+		db, err := NewDatabase(ctx, cfg, logger)
+		if err == nil || attempt >= cfg.DBConnectRetries {
+			return db, err
+		}
+
+		logger.Warn("Database unavailable, retrying", "error", err, "delay", delay, "attempt", attempt+1, "retries", cfg.DBConnectRetries)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxConnectDelay)
+	}
+}
+
+// execRetry executes a write statement, retrying while the database is busy
+func (db *Database) execRetry(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := db.retryBusy(ctx, func() error {
+		var err error
+		result, err = db.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+)
+
+// logSlow logs the named query when it has been running for longer than the threshold
+// Only the name is logged, never the SQL arguments, since they may hold user data
+func (db *Database) logSlow(name string, start time.Time) {
+	if db.slowQueryThreshold == 0 {
+		return
+	}
+
+	if elapsed := time.Since(start); elapsed >= db.slowQueryThreshold {
+		db.logger.Warn("Slow query", "name", name, "elapsed", elapsed.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"challenge/models"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// EventStore is the set of event operations shared by the transports, so the REST and
+// gRPC APIs read and write events through the same code
+type EventStore interface {
+	InsertEvent(ctx context.Context, event *models.Event, check InsertCheck) error
+	GetEventByID(ctx context.Context, id uuid.UUID) (*models.Event, error)
+	GetAllEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error)
+	CountEvents(ctx context.Context, filter models.EventFilter) (int, error)
+	UpdateEvent(ctx context.Context, event *models.Event, precondition func(*models.Event) bool) error
+	DeleteEvent(ctx context.Context, id uuid.UUID, precondition func(*models.Event) bool) error
+}
+
+// Database is the EventStore of the application
+var _ EventStore = (*Database)(nil)
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"challenge/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// The tags column keeps each event's own list, returned with the event, while the
+// event_tags table indexes them for filtering and counting. Both are written together.
+
+// writeTags replaces the indexed tags of an event using the given executor,
+// which should be the transaction writing the event
+func (db *Database) writeTags(ctx context.Context, exec execer, id uuid.UUID, tags []string) error {
+	if _, err := exec.ExecContext(ctx, db.sql(`DELETE FROM {prefix}event_tags WHERE event_id = ?`), id.String()); err != nil {
+		return fmt.Errorf("failed to clear event tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		_, err := exec.ExecContext(ctx,
+			db.sql(`INSERT OR IGNORE INTO {prefix}event_tags (event_id, tag) VALUES (?, ?)`),
+			id.String(),
+			tag,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store event tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetTags returns every tag in use with the number of events carrying it,
+// most used first and then alphabetically
+func (db *Database) GetTags(ctx context.Context) ([]models.TagCount, error) {
+	defer db.observe(ctx, "GetTags")()
+
+	query := `
+		SELECT t.tag, COUNT(*)
+		FROM {prefix}event_tags t
+		JOIN {prefix}events e ON e.id = t.event_id
+		WHERE e.deleted_at IS NULL
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag ASC
+	`
+
+	rows, err := db.Reader.QueryContext(ctx, db.sql(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []models.TagCount
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
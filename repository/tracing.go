@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of database operations
+var tracer = otel.Tracer("challenge/repository")
+
+// eventIDAttr is the span attribute holding the ID of the event an operation works on
+func eventIDAttr(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("event.id", id.String())
+}
+
+// QueryCounter counts the database operations run with a context from WithQueryCounter
+type QueryCounter struct {
+	count atomic.Int64
+}
+
+// Count returns how many database operations have run so far
+func (q *QueryCounter) Count() int64 {
+	return q.count.Load()
+}
+
+// queryCounterContextKey is the context key holding the QueryCounter of a request
+type queryCounterContextKey struct{}
+
+// WithQueryCounter returns a context whose database operations are counted by the returned counter
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	counter := &QueryCounter{}
+	return context.WithValue(ctx, queryCounterContextKey{}, counter), counter
+}
+
+// observe starts a child span of ctx for the named operation and returns the function
+// ending it, which also logs the operation when it was slow. The operation is counted by
+// the QueryCounter of ctx, if any.
+// It is meant to be deferred as: defer db.observe(ctx, "Name")()
+func (db *Database) observe(ctx context.Context, name string, attrs ...attribute.KeyValue) func() {
+	if counter, ok := ctx.Value(queryCounterContextKey{}).(*QueryCounter); ok {
+		counter.count.Add(1)
+	}
+
+	start := time.Now()
+	_, span := tracer.Start(ctx, "db."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "sqlite"),
+			attribute.String("db.operation.name", name),
+		),
+		trace.WithAttributes(attrs...),
+	)
+
+	return func() {
+		span.End()
+		db.logSlow(name, start)
+	}
+}
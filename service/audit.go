@@ -0,0 +1,49 @@
+package service
+
+import (
+	"challenge/repository"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// getEventHistory handles GET /events/:id/history
+// Returns the audit trail of an event, oldest change first. Deleted events keep their
+// trail; events created before auditing started have an empty one.
+func (s *Server) getEventHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	entries, err := s.DB.GetEventHistory(ctx, id)
+	if err != nil {
+		s.logger.Error("Error getting event history", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event history",
+		})
+	}
+
+	// An empty trail is only valid for an event that exists
+	if len(entries) == 0 {
+		if _, err := s.DB.GetEventByID(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrEventNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+					"error": "Event not found",
+				})
+			}
+			s.logger.Error("Error getting event by ID", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to retrieve event history",
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// HeaderAPIKey is the request header carrying the caller's API key
+const HeaderAPIKey = "X-API-Key"
+
+// principalContextKey is the echo context key holding the authenticated *Principal
+const principalContextKey = "principal"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID    string
+	Admin bool
+}
+
+// loadAPIKeys parses the configured key:user or key:user:admin entries
+// An empty result disables authentication
+func loadAPIKeys(entries []string, logger *slog.Logger) map[string]*Principal {
+	keys := make(map[string]*Principal)
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" ||
+			(len(parts) == 3 && parts[2] != "admin") {
+			logger.Warn("Ignoring invalid API_KEYS entry")
+			continue
+		}
+
+		keys[parts[0]] = &Principal{
+			ID:    parts[1],
+			Admin: len(parts) == 3,
+		}
+	}
+
+	return keys
+}
+
+// authenticate is a middleware resolving the caller from its API key
+// When no API keys are configured every request is allowed and has no principal
+func (s *Server) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if len(s.APIKeys) == 0 {
+			return next(c)
+		}
+
+		key := c.Request().Header.Get(HeaderAPIKey)
+		if key == "" {
+			key = strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		}
+
+		principal, ok := s.APIKeys[key]
+		if key == "" || !ok {
+			return echo.NewHTTPError(http.StatusUnauthorized, map[string]string{
+				"error": "Invalid or missing API key",
+			})
+		}
+
+		c.Set(principalContextKey, principal)
+		// Attribute the request's changes to the caller in the audit log
+		c.SetRequest(c.Request().WithContext(repository.WithActor(c.Request().Context(), principal.ID)))
+		return next(c)
+	}
+}
+
+// requireAdmin is a middleware rejecting non-admin callers with 403
+// When authentication is disabled there's no admin, so every caller is rejected: admin
+// routes like profiling and maintenance must never be open to anonymous callers
+func requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p := principal(c)
+		if p == nil {
+			return echo.NewHTTPError(http.StatusForbidden, map[string]string{
+				"error": "Admin access requires an admin API key in API_KEYS",
+			})
+		}
+		if !p.Admin {
+			return echo.NewHTTPError(http.StatusForbidden, map[string]string{
+				"error": "Admin access required",
+			})
+		}
+		return next(c)
+	}
+}
+
+// principal returns the authenticated caller, or nil when authentication is disabled
+func principal(c echo.Context) *Principal {
+	p, _ := c.Get(principalContextKey).(*Principal)
+	return p
+}
+
+// principalID returns the ID of the authenticated caller, or nil when authentication is disabled
+func principalID(c echo.Context) *string {
+	p := principal(c)
+	if p == nil {
+		return nil
+	}
+	return &p.ID
+}
+
+// mayModify reports whether the caller p may modify the event
+// Admins and unauthenticated deployments may modify any event, other callers only their own
+func mayModify(p *Principal, event *models.Event) bool {
+	if p == nil || p.Admin {
+		return true
+	}
+	return event.CreatedBy != nil && *event.CreatedBy == p.ID
+}
+
+// authorizeWrite checks that the caller may modify the event, see mayModify
+func authorizeWrite(c echo.Context, event *models.Event) error {
+	if !mayModify(principal(c), event) {
+		return echo.NewHTTPError(http.StatusForbidden, map[string]string{
+			"error": "You can only modify your own events",
+		})
+	}
+	return nil
+}
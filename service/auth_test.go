@@ -0,0 +1,42 @@
+package service
+
+import (
+	"challenge/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminRoutesRequireAdminKey(t *testing.T) {
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/maintenance/vacuum"},
+		{http.MethodPost, "/api/v1/maintenance/analyze"},
+		{http.MethodGet, "/debug/stats"},
+	}
+
+	t.Run("authentication disabled", func(t *testing.T) {
+		s := newTestServer(t, nil)
+		for _, route := range routes {
+			if rec := do(t, s, route.method, route.path, "", nil); rec.Code != http.StatusForbidden {
+				t.Errorf("%s %s: status %d, want %d", route.method, route.path, rec.Code, http.StatusForbidden)
+			}
+		}
+	})
+
+	t.Run("authentication enabled", func(t *testing.T) {
+		s := newTestServer(t, func(cfg *config.Config) {
+			cfg.APIKeys = []string{"admin-key:alice:admin", "user-key:bob"}
+		})
+		for _, route := range routes {
+			for key, want := range map[string]int{"admin-key": http.StatusOK, "user-key": http.StatusForbidden} {
+				req := httptest.NewRequest(route.method, route.path, nil)
+				req.Header.Set(HeaderAPIKey, key)
+				rec := httptest.NewRecorder()
+				s.Echo.ServeHTTP(rec, req)
+				if rec.Code != want {
+					t.Errorf("%s %s with %s: status %d, want %d", route.method, route.path, key, rec.Code, want)
+				}
+			}
+		}
+	})
+}
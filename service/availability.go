@@ -0,0 +1,109 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// AvailabilityResponse reports whether a slot is free and which events occupy it
+type AvailabilityResponse struct {
+	Available bool            `json:"available"`
+	Conflicts []*models.Event `json:"conflicts"`
+}
+
+// checkAvailability handles GET /events/availability
+// Reports the events overlapping [start, end) without creating anything
+func (s *Server) checkAvailability(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	start, err := s.parseTimeParam(c, "start", time.UTC)
+	if err != nil {
+		return err
+	}
+
+	end, err := s.parseTimeParam(c, "end", time.UTC)
+	if err != nil {
+		return err
+	}
+
+	if !end.After(start) {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "end should be after start",
+		})
+	}
+
+	// Editing an event shouldn't conflict with itself
+	var exclude uuid.UUID
+	if value := c.QueryParam("exclude"); value != "" {
+		exclude, err = uuid.Parse(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": "Invalid exclude, expected a UUID",
+			})
+		}
+	}
+
+	conflicts, err := s.DB.FindOverlappingEvents(ctx, start, end, exclude)
+	if err != nil {
+		s.logger.Error("Error checking availability", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to check availability",
+		})
+	}
+
+	if conflicts == nil {
+		conflicts = []*models.Event{}
+	}
+
+	return c.JSON(http.StatusOK, AvailabilityResponse{
+		Available: len(conflicts) == 0,
+		Conflicts: conflicts,
+	})
+}
+
+// getEventConflicts handles GET /events/:id/conflicts
+// Returns the other events overlapping a stored event's time range, ordered by start time,
+// using the same overlap rules as the availability check
+func (s *Server) getEventConflicts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	conflicts, err := s.DB.FindOverlappingEvents(ctx, event.StartTime, event.EndTime, event.ID)
+	if err != nil {
+		s.logger.Error("Error finding conflicting events", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	if conflicts == nil {
+		conflicts = []*models.Event{}
+	}
+
+	return c.JSON(http.StatusOK, conflicts)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// defaultCacheControl is the Cache-Control of responses without a configured directive
+// Nothing is stored unless an operator decides a route is safe to cache
+const defaultCacheControl = "no-store"
+
+// cacheRouteKey identifies a route in CACHE_CONTROL, as its method and path without the base path
+func cacheRouteKey(method, path string) string {
+	return method + " " + path
+}
+
+// setCacheControl is a middleware setting the Cache-Control header configured for the
+// matched route, or no-store. Directives only apply to successful and 304 responses, so
+// errors like a 404 are never cached; handlers setting the header themselves, like the
+// event stream, keep theirs.
+func (s *Server) setCacheControl(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		directive, ok := s.cacheControl[cacheRouteKey(c.Request().Method, strings.TrimPrefix(c.Path(), s.basePath))]
+		res.Before(func() {
+			if res.Header().Get(echo.HeaderCacheControl) != "" {
+				return
+			}
+			cacheable := res.Status < 300 || res.Status == http.StatusNotModified
+			if !ok || !cacheable {
+				directive = defaultCacheControl
+			}
+			res.Header().Set(echo.HeaderCacheControl, directive)
+		})
+		return next(c)
+	}
+}
+
+// checkCacheRoutes warns about configured Cache-Control routes that match no registered
+// route, usually a typo or a missing method
+func (s *Server) checkCacheRoutes() {
+	routes := make(map[string]bool)
+	for _, route := range s.Echo.Routes() {
+		routes[cacheRouteKey(route.Method, strings.TrimPrefix(route.Path, s.basePath))] = true
+	}
+	for route := range s.cacheControl {
+		if !routes[route] {
+			s.logger.Warn("Cache-Control configured for an unknown route", "route", route)
+		}
+	}
+}
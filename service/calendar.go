@@ -0,0 +1,360 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"challenge/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// dayFormat is the key format used when bucketing events by day
+const dayFormat = "2006-01-02"
+
+// parseTimeParam parses a time query parameter, an ISO 8601 timestamp or a relative
+// expression like now+7d whose today and tomorrow are taken in loc
+func (s *Server) parseTimeParam(c echo.Context, name string, loc *time.Location) (time.Time, error) {
+	value := c.QueryParam(name)
+
+	t, err := s.rules.ParseTimestamp(value)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = utils.ParseTimeExpression(value, time.Now().In(loc))
+	if err == nil {
+		// Stored timestamps compare as UTC text
+		return t.UTC(), nil
+	}
+	if !errors.Is(err, utils.ErrNotTimeExpression) {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid " + name + ": " + err.Error(),
+		})
+	}
+	return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+		"error": "Invalid or missing " + name + ", expected ISO 8601 format or an expression like now+7d",
+	})
+}
+
+// parseRange parses the required from/to query parameters, with today and tomorrow taken in loc
+func (s *Server) parseRange(c echo.Context, loc *time.Location) (time.Time, time.Time, error) {
+	from, err := s.parseTimeParam(c, "from", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to, err := s.parseTimeParam(c, "to", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "to should be after from",
+		})
+	}
+
+	return from, to, nil
+}
+
+// checkRangeSpan rejects ranges longer than maxRangeSpan with 400, since every occurrence of
+// a recurring event within the range is built in memory
+func (s *Server) checkRangeSpan(from, to time.Time) error {
+	if s.maxRangeSpan == 0 || to.Sub(from) <= s.maxRangeSpan {
+		return nil
+	}
+
+	span := s.maxRangeSpan.String()
+	if s.maxRangeSpan%(24*time.Hour) == 0 {
+		span = fmt.Sprintf("%d days", s.maxRangeSpan/(24*time.Hour))
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+		"error": "The range must not span more than " + span,
+	})
+}
+
+// parseOptionalTime parses an optional ISO 8601 or relative query parameter, in UTC
+// A missing parameter yields the zero time
+func (s *Server) parseOptionalTime(c echo.Context, name string) (time.Time, error) {
+	if c.QueryParam(name) == "" {
+		return time.Time{}, nil
+	}
+	return s.parseTimeParam(c, name, time.UTC)
+}
+
+// parseOptionalDuration parses an optional Go duration query parameter such as 90m
+// A missing parameter yields zero; negative durations are rejected
+func parseOptionalDuration(c echo.Context, name string) (time.Duration, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Invalid %s %q, expected a duration such as 2h", name, value),
+		})
+	}
+	return d, nil
+}
+
+// parseLocation parses the optional tz query parameter, defaulting to UTC
+func parseLocation(c echo.Context) (*time.Location, error) {
+	tz := c.QueryParam("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Unknown time zone",
+		})
+	}
+	return loc, nil
+}
+
+// listEventsByDay handles GET /events/by-day
+// Returns events starting within [from, to) bucketed by their start date in the tz time zone
+// Recurring events are expanded into each of their occurrences in the range
+func (s *Server) listEventsByDay(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := s.parseRange(c, loc)
+	if err != nil {
+		return err
+	}
+	if err := s.checkRangeSpan(from, to); err != nil {
+		return err
+	}
+
+	events, err := s.DB.GetEventsInRange(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Error getting events in range", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	var occurrences []*models.Event
+	for _, event := range events {
+		occurrences = append(occurrences, event.Occurrences(from, to)...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartTime.Before(occurrences[j].StartTime)
+	})
+
+	days := make(map[string][]*models.Event)
+	for _, occurrence := range occurrences {
+		day := occurrence.StartTime.In(loc).Format(dayFormat)
+		days[day] = append(days[day], occurrence)
+	}
+
+	return c.JSON(http.StatusOK, days)
+}
+
+// dayBounds returns the start of the calendar day containing t in loc and the start of the next one
+// Days are computed by calendar date, so they last 23 or 25 hours across DST transitions
+func dayBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// listEventsToday handles GET /events/today
+// Returns the events, and occurrences of recurring events, starting during the current
+// calendar day in the tz time zone, ordered by start time
+func (s *Server) listEventsToday(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		return err
+	}
+
+	// Stored timestamps compare as UTC text, so query with UTC bounds
+	from, to := dayBounds(time.Now(), loc)
+	from, to = from.UTC(), to.UTC()
+
+	events, err := s.DB.GetEventsInRange(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Error getting events in range", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	occurrences := []*models.Event{}
+	for _, event := range events {
+		occurrences = append(occurrences, event.Occurrences(from, to)...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartTime.Before(occurrences[j].StartTime)
+	})
+
+	return c.JSON(http.StatusOK, occurrences)
+}
+
+// parseCalendarTime parses a FullCalendar range bound, a timestamp or a date in loc
+func (s *Server) parseCalendarTime(c echo.Context, name string, loc *time.Location) (time.Time, error) {
+	value := c.QueryParam(name)
+
+	t, err := s.rules.ParseTimestamp(value)
+	if err != nil {
+		t, err = time.ParseInLocation(dayFormat, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid or missing " + name + ", expected ISO 8601 format",
+		})
+	}
+	return t, nil
+}
+
+// toCalendarEvent maps an event to the FullCalendar event shape
+// An event running from midnight to midnight in loc is an all-day event, given as dates
+func toCalendarEvent(event *models.Event, loc *time.Location) models.CalendarEvent {
+	start, end := event.StartTime.In(loc), event.EndTime.In(loc)
+	startDay, _ := dayBounds(start, loc)
+	endDay, _ := dayBounds(end, loc)
+
+	if start.Equal(startDay) && end.Equal(endDay) && end.After(start) {
+		return models.CalendarEvent{
+			ID:     event.ID,
+			Title:  event.Title,
+			Start:  start.Format(dayFormat),
+			End:    end.Format(dayFormat),
+			AllDay: true,
+		}
+	}
+	return models.CalendarEvent{
+		ID:    event.ID,
+		Title: event.Title,
+		Start: event.StartTime.Format(time.RFC3339),
+		End:   event.EndTime.Format(time.RFC3339),
+	}
+}
+
+// listFullCalendarEvents handles GET /events/fullcalendar
+// Returns the events, and occurrences of recurring events, starting within [start, end) in
+// the shape FullCalendar's JSON feed expects. The parameter names follow the feed too:
+// start and end bound the range and timeZone, defaulting to UTC, decides all-day events.
+func (s *Server) listFullCalendarEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	loc := time.UTC
+	if tz := c.QueryParam("timeZone"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": "Unknown time zone",
+			})
+		}
+	}
+
+	from, err := s.parseCalendarTime(c, "start", loc)
+	if err != nil {
+		return err
+	}
+	to, err := s.parseCalendarTime(c, "end", loc)
+	if err != nil {
+		return err
+	}
+	if to.Before(from) {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "end should be after start",
+		})
+	}
+	if err := s.checkRangeSpan(from, to); err != nil {
+		return err
+	}
+
+	// Stored timestamps compare as UTC text, so query with UTC bounds
+	from, to = from.UTC(), to.UTC()
+
+	events, err := s.DB.GetEventsInRange(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Error getting events in range", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	var occurrences []*models.Event
+	for _, event := range events {
+		occurrences = append(occurrences, event.Occurrences(from, to)...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartTime.Before(occurrences[j].StartTime)
+	})
+
+	feed := make([]models.CalendarEvent, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		feed = append(feed, toCalendarEvent(occurrence, loc))
+	}
+
+	return c.JSON(http.StatusOK, feed)
+}
+
+// listOccurrences handles GET /events/:id/occurrences
+// Returns the start and end time of every occurrence of a recurring event starting within
+// [from, to), computed from its recurrence rather than stored. Events without recurrence get 400.
+func (s *Server) listOccurrences(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	from, to, err := s.parseRange(c, time.UTC)
+	if err != nil {
+		return err
+	}
+	if err := s.checkRangeSpan(from, to); err != nil {
+		return err
+	}
+
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	if event.Recurrence == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Event has no recurrence",
+		})
+	}
+
+	occurrences := []models.Occurrence{}
+	for _, occurrence := range event.Occurrences(from, to) {
+		occurrences = append(occurrences, models.Occurrence{
+			StartTime: occurrence.StartTime,
+			EndTime:   occurrence.EndTime,
+		})
+	}
+
+	return c.JSON(http.StatusOK, occurrences)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"challenge/models"
+	"fmt"
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// HeaderClientID is the request header carrying the temporary ID an offline client gave
+// the event it creates, echoed back with the server's ID
+const HeaderClientID = "Client-Id"
+
+// MaxClientIDLength is the longest Client-Id accepted
+const MaxClientIDLength = 200
+
+// validateClientID rejects a Client-Id header too long to echo back
+// Any other value is accepted, client IDs needn't be UUIDs
+func validateClientID(c echo.Context) error {
+	if len(c.Request().Header.Get(HeaderClientID)) > MaxClientIDLength {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("%s must be at most %d characters", HeaderClientID, MaxClientIDLength),
+		})
+	}
+	return nil
+}
+
+// respondClientID writes the mapping from the client's temporary ID to the created event's
+// ID, with the event unless the client prefers minimal responses. Nothing is stored, the
+// header is only echoed. JSON:API clients get the event with the client ID as its lid.
+func (s *Server) respondClientID(c echo.Context, status int, clientID string, event *models.Event) error {
+	c.Response().Header().Set(HeaderClientID, clientID)
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	if wantsJSONAPI(c) {
+		resource, err := toJSONAPIResource(event, nil)
+		if err != nil {
+			s.logger.Error("Error encoding JSON:API resource", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to retrieve event",
+			})
+		}
+		resource.LID = clientID
+		return respondJSONAPI(c, status, jsonAPIDocument{
+			Data:  resource,
+			Links: map[string]string{"self": eventURL(c, event)},
+		})
+	}
+
+	mapping := models.ClientIDMapping{ClientID: clientID, ServerID: event.ID}
+	if prefersMinimal(c) {
+		c.Response().Header().Set(HeaderPreferenceApplied, "return=minimal")
+	} else {
+		mapping.Event = event
+	}
+	return c.JSON(status, mapping)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// cloneEvent handles POST /events/:id/clone
+// Creates a copy of the event on target_date at the same time of day with the same duration,
+// moving its recurrence along, for "copy last week's schedule" workflows. The copy is a new
+// event owned by the caller and goes through the booking policy like any creation.
+func (s *Server) cloneEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	var req models.CloneRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	clone := event.CloneTo(req.Target(event))
+	clone.CreatedBy = principalID(c)
+
+	// The moved recurrence is checked again, as when events are shifted
+	if clone.Recurrence != nil {
+		if err := clone.Recurrence.Validate(clone.StartTime); err != nil {
+			return err
+		}
+	}
+
+	if err := s.DB.InsertEvent(ctx, clone, s.policyCheck(clone, principal(c))); err != nil {
+		if errors.Is(err, ErrWindowLimitExceeded) || errors.Is(err, ErrOwnerLimitExceeded) {
+			return s.policyError(c, err, "Failed to clone event")
+		}
+		s.logger.Error("Error inserting cloned event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to clone event",
+		})
+	}
+
+	s.Counter.Add(1)
+	s.Hub.Publish(EventChange{Type: ChangeCreated, Event: clone})
+
+	return s.respondCreated(c, http.StatusCreated, clone)
+}
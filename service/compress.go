@@ -0,0 +1,69 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// compressResponses returns the middleware gzip compressing responses of at least minLength
+// bytes, for clients accepting it, at the given level
+func (s *Server) compressResponses(level, minLength int) echo.MiddlewareFunc {
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		Skipper:   s.skipCompression,
+		Level:     level,
+		MinLength: minLength,
+	})
+}
+
+// skipCompression reports whether a response must be sent as the handler writes it
+// The event stream would be held back by the compressor's buffer, the export compresses
+// itself and serves byte ranges of the uncompressed body, and profiles are already gzipped.
+// The middleware only looks for "gzip" in Accept-Encoding, so clients refusing it with q=0
+// are skipped here.
+func (s *Server) skipCompression(c echo.Context) bool {
+	if !acceptsGzip(c.Request().Header.Get(echo.HeaderAcceptEncoding)) {
+		return true
+	}
+	switch c.Path() {
+	case s.basePath + "/api/v1/events/stream", s.basePath + "/api/v1/events/export":
+		return true
+	}
+	return strings.HasPrefix(c.Path(), s.basePath+"/debug/pprof")
+}
+
+// acceptsGzip reports whether an Accept-Encoding header accepts gzip, named or through *,
+// with a nonzero quality
+func acceptsGzip(header string) bool {
+	named, wildcard := -1.0, -1.0
+
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					quality = 0
+				} else {
+					quality = q
+				}
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip", "x-gzip":
+			named = quality
+		case "*":
+			wildcard = quality
+		}
+	}
+
+	// A coding named explicitly takes precedence over the wildcard
+	if named >= 0 {
+		return named > 0
+	}
+	return wildcard > 0
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"challenge/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"GZIP; Q=1", true},
+		{"x-gzip", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0", false},
+		{"br, gzip;q=0, *", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"br;q=1, gzip;q=bogus", false},
+		{"identity", false},
+	}
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRefusedGzipIsNotSent(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.GzipLevel = 5
+		cfg.GzipMinLength = 1
+	})
+
+	const body = `{"title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`
+	if rec := do(t, s, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, path := range []string{"/api/v1/events", "/api/v1/events/export"} {
+		for encoding, want := range map[string]string{"gzip": "gzip", "gzip;q=0": "", "gzip;q=0, identity": ""} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set(echo.HeaderAcceptEncoding, encoding)
+			rec := httptest.NewRecorder()
+			s.Echo.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET %s: status %d: %s", path, rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get(echo.HeaderContentEncoding); got != want {
+				t.Errorf("GET %s with Accept-Encoding %q: Content-Encoding %q, want %q", path, encoding, got, want)
+			}
+			if want == "" && !strings.Contains(rec.Body.String(), "Planning") {
+				t.Errorf("GET %s with Accept-Encoding %q: body %q, want the event uncompressed", path, encoding, rec.Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"challenge/models"
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// EventCounter caches the number of stored events so reads don't scan the table
+// It is adjusted on every insert and delete, and periodically reconciled with the
+// database to correct drift from writes it didn't see
+type EventCounter struct {
+	count atomic.Int64
+}
+
+// Load returns the cached count
+func (ec *EventCounter) Load() int64 {
+	return ec.count.Load()
+}
+
+// Add adjusts the cached count by delta
+func (ec *EventCounter) Add(delta int64) {
+	ec.count.Add(delta)
+}
+
+// Store replaces the cached count, returning the previous value
+func (ec *EventCounter) Store(count int64) int64 {
+	return ec.count.Swap(count)
+}
+
+// reconcileCount replaces the cached count with the current number of events in the database
+func (s *Server) reconcileCount(ctx context.Context) error {
+	count, err := s.DB.CountEvents(ctx, models.EventFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile event count: %w", err)
+	}
+
+	if previous := s.Counter.Store(int64(count)); previous != int64(count) {
+		s.logger.Info("Reconciled event count", "from", previous, "to", count)
+	}
+	return nil
+}
+
+// runReconciler reconciles the cached count every interval until ctx is done
+func (s *Server) runReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.reconcileCount(ctx); err != nil {
+				s.logger.Error("Error reconciling event count", "error", err)
+			}
+		}
+	}
+}
+
+// countEvents handles GET /events/count
+// Returns the cached number of events
+func (s *Server) countEvents(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]int64{
+		"count": s.Counter.Load(),
+	})
+}
+
+// getEventStats handles GET /events/stats
+// Returns aggregate counts, start time bounds and the average duration of the events
+func (s *Server) getEventStats(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	stats, err := s.DB.GetEventStats(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		s.logger.Error("Error getting event stats", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event stats",
+		})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// metrics handles GET /metrics
+// Exposes the cached event count in the Prometheus text format
+func (s *Server) metrics(c echo.Context) error {
+	body := fmt.Sprintf(
+		"# HELP events_total Number of stored events.\n# TYPE events_total gauge\nevents_total %d\n",
+		s.Counter.Load(),
+	)
+	return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(body))
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"challenge/models"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestEventCounterConcurrentAdds(t *testing.T) {
+	const goroutines, adds = 50, 1000
+
+	var counter EventCounter
+	var wg sync.WaitGroup
+	var want int64
+	for i := 0; i < goroutines; i++ {
+		// Every third goroutine removes events instead of adding them
+		delta := int64(1)
+		if i%3 == 0 {
+			delta = -1
+		}
+		want += delta * adds
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < adds; j++ {
+				counter.Add(delta)
+				counter.Load()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := counter.Load(); got != want {
+		t.Errorf("count = %d, want %d", got, want)
+	}
+}
+
+func TestEventCounterFollowsConcurrentWrites(t *testing.T) {
+	const events = 20
+
+	s := newTestServer(t, nil)
+
+	ids := make(chan string, events)
+	var wg sync.WaitGroup
+	for i := 0; i < events; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var created models.Event
+			body := fmt.Sprintf(`{"title":"Event %d","start_time":"2025-03-%02dT10:00:00Z","end_time":"2025-03-%02dT11:00:00Z"}`, i, i+1, i+1)
+			if rec := do(t, s, http.MethodPost, "/api/v1/events", body, &created); rec.Code != http.StatusCreated {
+				t.Errorf("create: status %d: %s", rec.Code, rec.Body)
+				return
+			}
+			ids <- created.ID.String()
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	// Delete half of them while the other half is left alone
+	var deleted int
+	for id := range ids {
+		if deleted == events/2 {
+			break
+		}
+		deleted++
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if rec := do(t, s, http.MethodDelete, "/api/v1/events/"+id, "", nil); rec.Code != http.StatusNoContent {
+				t.Errorf("delete: status %d: %s", rec.Code, rec.Body)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	stored, err := s.DB.CountEvents(context.Background(), models.EventFilter{})
+	if err != nil {
+		t.Fatalf("CountEvents: %v", err)
+	}
+	if got := s.Counter.Load(); got != int64(stored) || stored != events-deleted {
+		t.Errorf("cached count = %d, stored count = %d, want both %d", got, stored, events-deleted)
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// limitWriteTime returns the middleware giving each response timeout to be written
+// It replaces the server's WriteTimeout, which would cut off the long responses too. Those
+// have their deadline cleared instead, since one set by an earlier request on the same
+// connection would otherwise still apply.
+func (s *Server) limitWriteTime(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			deadline := time.Now().Add(timeout)
+			if s.longResponse(c) {
+				deadline = time.Time{}
+			}
+			// Only fails for writers without deadlines, such as test recorders
+			_ = http.NewResponseController(c.Response().Writer).SetWriteDeadline(deadline)
+			return next(c)
+		}
+	}
+}
+
+// longResponse reports whether a response is written for as long as it takes
+// The event stream stays open while the client listens, exports grow with the events stored
+// and CPU profiles last as long as requested.
+func (s *Server) longResponse(c echo.Context) bool {
+	switch c.Path() {
+	case s.basePath + "/api/v1/events/stream", s.basePath + "/api/v1/events/export", s.basePath + "/api/v1/events/export.ics":
+		return true
+	}
+	return strings.HasPrefix(c.Path(), s.basePath+"/debug/pprof")
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bufio"
+	"challenge/config"
+	"challenge/models"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// startHTTPServer serves s over a real connection with the timeouts of its own http.Server
+func startHTTPServer(t *testing.T, s *Server) string {
+	t.Helper()
+
+	ts := httptest.NewUnstartedServer(s.Echo)
+	ts.Config.ReadTimeout = s.Echo.Server.ReadTimeout
+	ts.Config.WriteTimeout = s.Echo.Server.WriteTimeout
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts.Listener.Addr().String()
+}
+
+func TestWriteTimeoutSparesTheEventStream(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.WriteTimeout = config.Duration(timeout)
+	})
+	s.Echo.GET("/slow", func(c echo.Context) error {
+		time.Sleep(3 * timeout)
+		return c.String(http.StatusOK, "done")
+	})
+	addr := startHTTPServer(t, s)
+
+	// An ordinary route is still bound by the timeout
+	resp, err := http.Get("http://" + addr + "/slow")
+	if err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		t.Errorf("slow route: got %q, want the response cut off", body)
+	}
+
+	// A request on the same connection before the stream leaves its deadline behind
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	fmt.Fprint(conn, "GET /api/v1/events HTTP/1.1\r\nHost: test\r\n\r\n")
+	resp, err = http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("listing: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	fmt.Fprint(conn, "GET /api/v1/events/stream HTTP/1.1\r\nHost: test\r\n\r\n")
+	resp, err = http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("opening the stream: %v", err)
+	}
+	// Closing the body would drain the stream, so only the connection is closed
+
+	time.Sleep(3 * timeout)
+	s.Hub.Publish(EventChange{Type: ChangeCreated, Event: &models.Event{ID: uuid.New(), Title: "Planning"}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		if strings.HasPrefix(lines.Text(), "event: ") {
+			return
+		}
+	}
+	t.Errorf("stream ended before the change arrived: %v", lines.Err())
+}
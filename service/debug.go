@@ -0,0 +1,93 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// registerPprof mounts the net/http/pprof handlers under /debug/pprof on the group
+func registerPprof(debug *echo.Group) {
+	debug.GET("/pprof/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	debug.GET("/pprof/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	debug.GET("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	debug.POST("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	debug.GET("/pprof/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	// Index serves the listing and every named profile like heap or goroutine
+	debug.GET("/pprof/*", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+}
+
+// DBStats reports the state of the database connection pool
+type DBStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+// MemoryStats reports a subset of the Go runtime memory statistics, in bytes
+type MemoryStats struct {
+	Alloc        uint64 `json:"alloc"`
+	TotalAlloc   uint64 `json:"total_alloc"`
+	Sys          uint64 `json:"sys"`
+	HeapAlloc    uint64 `json:"heap_alloc"`
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+}
+
+// RuntimeStats is the response of GET /debug/stats
+// DB is the write connection and ReadDB the read pool, the same for an in-memory database
+type RuntimeStats struct {
+	DB         DBStats     `json:"db"`
+	ReadDB     DBStats     `json:"read_db"`
+	Goroutines int         `json:"goroutines"`
+	Memory     MemoryStats `json:"memory"`
+}
+
+// poolStats converts the statistics of a connection pool
+func poolStats(db sql.DBStats) DBStats {
+	return DBStats{
+		MaxOpenConnections: db.MaxOpenConnections,
+		OpenConnections:    db.OpenConnections,
+		InUse:              db.InUse,
+		Idle:               db.Idle,
+		WaitCount:          db.WaitCount,
+		WaitDurationMs:     db.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      db.MaxIdleClosed,
+		MaxIdleTimeClosed:  db.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  db.MaxLifetimeClosed,
+	}
+}
+
+// debugStats handles GET /debug/stats
+// Returns connection pool, goroutine and memory statistics for diagnosing leaks
+func (s *Server) debugStats(c echo.Context) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return c.JSON(http.StatusOK, RuntimeStats{
+		DB:         poolStats(s.DB.DB.Stats()),
+		ReadDB:     poolStats(s.DB.Reader.Stats()),
+		Goroutines: runtime.NumGoroutine(),
+		Memory: MemoryStats{
+			Alloc:        mem.Alloc,
+			TotalAlloc:   mem.TotalAlloc,
+			Sys:          mem.Sys,
+			HeapAlloc:    mem.HeapAlloc,
+			HeapInuse:    mem.HeapInuse,
+			HeapObjects:  mem.HeapObjects,
+			NumGC:        mem.NumGC,
+			PauseTotalNs: mem.PauseTotalNs,
+		},
+	})
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// dedupEntry is the first request seen with a given body within the window
+// done is closed once its response is recorded; response stays nil when it failed
+type dedupEntry struct {
+	expires  time.Time
+	done     chan struct{}
+	response *bufferedResponse
+}
+
+// Deduplicator remembers recent create requests so identical ones sent moments later,
+// like those of a double-clicked submit button, get the first response instead of
+// creating the event twice. It is a cheaper guard than idempotency keys, kept in memory.
+type Deduplicator struct {
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// NewDeduplicator creates a deduplicator remembering requests for window
+func NewDeduplicator(window time.Duration) *Deduplicator {
+	return &Deduplicator{
+		window:  window,
+		entries: make(map[string]*dedupEntry),
+	}
+}
+
+// claim returns the entry of key, and whether it was just created for the caller
+// Expired entries are dropped on the way
+func (d *Deduplicator) claim(key string, now time.Time) (*dedupEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, entry := range d.entries {
+		if now.After(entry.expires) {
+			delete(d.entries, k)
+		}
+	}
+
+	if entry, ok := d.entries[key]; ok {
+		return entry, false
+	}
+	entry := &dedupEntry{expires: now.Add(d.window), done: make(chan struct{})}
+	d.entries[key] = entry
+	return entry, true
+}
+
+// forget removes the entry of key, so a failed request can be retried right away
+func (d *Deduplicator) forget(key string, entry *dedupEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.entries[key] == entry {
+		delete(d.entries, key)
+	}
+}
+
+// dedupKey hashes what makes two requests duplicates: the caller, the URL and the body
+func dedupKey(c echo.Context, body []byte) string {
+	hash := sha256.New()
+	if id := principalID(c); id != nil {
+		hash.Write([]byte(*id))
+	}
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Request().URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// dedupRequests is a middleware answering a request identical to one seen within the
+// dedup window with the first one's response. Duplicates arriving while the first is still
+// running wait for it. Only successful responses are replayed, and requests carrying an
+// Idempotency-Key are left to that mechanism.
+func (s *Server) dedupRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.dedup == nil || c.Request().Header.Get(HeaderIdempotencyKey) != "" {
+			return next(c)
+		}
+
+		req := c.Request()
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": "Invalid request payload",
+			})
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := dedupKey(c, body)
+		entry, first := s.dedup.claim(key, time.Now())
+		if !first {
+			select {
+			case <-entry.done:
+			case <-req.Context().Done():
+				return req.Context().Err()
+			}
+			if entry.response == nil {
+				return next(c)
+			}
+			s.logger.Debug("Replaying duplicate request", "uri", req.URL.RequestURI())
+			return writeHeld(c.Response(), entry.response)
+		}
+
+		defer close(entry.done)
+		res := c.Response()
+		before := res.Header()
+		held := &bufferedResponse{header: before.Clone()}
+		c.SetResponse(echo.NewResponse(held, s.Echo))
+
+		// Write the error response into the held one, like countQueries
+		err = next(c)
+		if err != nil {
+			c.Error(err)
+		}
+		c.SetResponse(res)
+
+		if held.status >= 200 && held.status < 300 {
+			// Only the headers the handler set are replayed, duplicates keep their own trace ID
+			replay := &bufferedResponse{header: http.Header{}, status: held.status}
+			replay.body.Write(held.body.Bytes())
+			for name, values := range held.header {
+				if !slices.Equal(before[name], values) {
+					replay.header[name] = values
+				}
+			}
+			entry.response = replay
+		} else {
+			s.dedup.forget(key, entry)
+		}
+		return writeHeld(res, held)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// statusCodes are the error codes of responses that don't carry a more specific one
+var statusCodes = map[int]string{
+	http.StatusBadRequest:                   "BAD_REQUEST",
+	http.StatusUnauthorized:                 "UNAUTHORIZED",
+	http.StatusForbidden:                    "FORBIDDEN",
+	http.StatusNotFound:                     "NOT_FOUND",
+	http.StatusMethodNotAllowed:             "METHOD_NOT_ALLOWED",
+	http.StatusConflict:                     "CONFLICT",
+	http.StatusPreconditionFailed:           "PRECONDITION_FAILED",
+	http.StatusRequestEntityTooLarge:        "PAYLOAD_TOO_LARGE",
+	http.StatusUnsupportedMediaType:         "UNSUPPORTED_MEDIA_TYPE",
+	http.StatusRequestedRangeNotSatisfiable: "RANGE_NOT_SATISFIABLE",
+	http.StatusUnprocessableEntity:          "UNPROCESSABLE_ENTITY",
+	http.StatusTooManyRequests:              "TOO_MANY_REQUESTS",
+	http.StatusInternalServerError:          "INTERNAL_ERROR",
+	http.StatusServiceUnavailable:           "SERVICE_UNAVAILABLE",
+}
+
+// errorHandler writes every error as the {"error": ..., "code": ...} envelope
+// Handlers set a specific code where they have one; otherwise it is derived from the status.
+// Validation failures returned as is, such as those of c.Validate, become 400 responses.
+func (s *Server) errorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	if isValidationError(err) {
+		err = validationError(c, err)
+	}
+
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		s.logger.Error("Unhandled error", "error", err)
+		he = echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	body := map[string]interface{}{}
+	switch message := he.Message.(type) {
+	case map[string]string:
+		for key, value := range message {
+			body[key] = value
+		}
+	case map[string]interface{}:
+		for key, value := range message {
+			body[key] = value
+		}
+	case string:
+		body["error"] = message
+	case error:
+		body["error"] = message.Error()
+	}
+	if _, ok := body["error"]; !ok {
+		body["error"] = http.StatusText(he.Code)
+	}
+	if _, ok := body["code"]; !ok {
+		code, ok := statusCodes[he.Code]
+		if !ok {
+			code = "ERROR"
+		}
+		body["code"] = code
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(he.Code)
+	} else {
+		err = c.JSON(he.Code, body)
+	}
+	if err != nil {
+		s.logger.Error("Error writing error response", "error", err)
+	}
+}
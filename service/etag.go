@@ -0,0 +1,73 @@
+package service
+
+import (
+	"challenge/models"
+	"fmt"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// weakETag builds a weak entity tag from the number of matching events and their latest update
+func weakETag(count int, maxUpdated time.Time) string {
+	var version int64
+	if !maxUpdated.IsZero() {
+		version = maxUpdated.UnixNano()
+	}
+	return fmt.Sprintf(`W/"%d-%d"`, count, version)
+}
+
+// eventETag builds the strong entity tag of a single event from its last update,
+// which changes on every write
+func eventETag(event *models.Event) string {
+	return fmt.Sprintf(`"%d"`, event.UpdatedAt.UnixNano())
+}
+
+// ifMatch reports whether etag satisfies the request's If-Match header, and whether the
+// header was sent at all. Comparison is strong, as required for If-Match, so weak tags never match.
+func ifMatch(c echo.Context, etag string) (matches bool, present bool) {
+	header := c.Request().Header.Get("If-Match")
+	if header == "" {
+		return false, false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// ifMatchPrecondition returns the precondition of a conditional write, nil without If-Match
+// The repository checks it in the write's transaction so a concurrent update can't slip in
+// between reading the event and changing it
+func ifMatchPrecondition(c echo.Context) func(*models.Event) bool {
+	if c.Request().Header.Get("If-Match") == "" {
+		return nil
+	}
+	return func(current *models.Event) bool {
+		matches, _ := ifMatch(c, eventETag(current))
+		return matches
+	}
+}
+
+// etagMatches reports whether the request's If-None-Match header matches etag
+// Comparison is weak, as required for If-None-Match, so W/ prefixes are ignored
+func etagMatches(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
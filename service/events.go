@@ -1,40 +1,150 @@
 package service
 
 import (
+	"challenge/config"
 	"challenge/models"
 	"challenge/repository"
-	"challenge/utils"
 	"context"
-	"log"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	echo "github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"google.golang.org/grpc"
 )
 
-// Server holds the Echo instance and database
+// HeaderIdempotencyKey is the request header carrying a client chosen key
+// that makes event creation and full updates safe to retry
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// MIMEMergePatchJSON is the JSON Merge Patch (RFC 7396) media type
+const MIMEMergePatchJSON = "application/merge-patch+json"
+
+// Server holds the Echo instance, database, change hub, booking policy and API keys
 type Server struct {
-	Echo *echo.Echo
-	DB   *repository.Database
+	Echo    *echo.Echo
+	DB      *repository.Database
+	Hub     *Hub
+	Policy  Policy
+	APIKeys map[string]*Principal
+	Counter *EventCounter
+
+	logger            *slog.Logger
+	reconcileInterval time.Duration
+	// retention is how long after their end events are kept, zero keeps them forever
+	retention       time.Duration
+	cleanupInterval time.Duration
+	// pprof mounts the profiling handlers
+	pprof bool
+	// shutdownTimeout bounds how long in-flight requests get to finish on shutdown
+	shutdownTimeout time.Duration
+	// readOnly rejects writes, both from clients and the cleanup job
+	readOnly bool
+	// basePath prefixes every route, empty to serve them from the root
+	basePath string
+	// maintenance is held while a maintenance task like VACUUM runs
+	maintenance sync.Mutex
+	// backupPath is where backups are written, empty when they are disabled
+	backupPath string
+	// queryBudget is how many database operations a request may run, zero for no limit
+	queryBudget int
+	// devMode fails requests over the query budget instead of only logging them
+	devMode bool
+	// grpcPort is where the gRPC API listens, empty to disable it
+	grpcPort string
+	// expensive holds a slot for each running export or batch request, nil for no limit
+	expensive chan struct{}
+	// maxRangeSpan is the longest range recurring events are expanded over, zero for no limit
+	maxRangeSpan time.Duration
+	// rules are the validation rules, which also decide how query timestamps are parsed
+	rules models.Rules
+	// dedup replays the response of identical create requests, nil when disabled
+	dedup *Deduplicator
+	// cacheControl is the Cache-Control directive of each route, keyed by cacheRouteKey
+	cacheControl map[string]string
+	// allowed is the Allow header of every route path, built by registerOptions
+	allowed map[string]string
 }
 
-// NewServer creates a new server instance
-func NewServer(db *repository.Database) *Server {
+// NewServer creates a new server instance logging to logger
+func NewServer(db *repository.Database, cfg config.Config, logger *slog.Logger) *Server {
 	e := echo.New()
+	rules := rulesFromConfig(cfg)
+	e.Validator = newRequestValidator(rules)
+	// The write timeout is applied per route by limitWriteTime
+	for _, srv := range []*http.Server{e.Server, e.TLSServer} {
+		srv.ReadTimeout = time.Duration(cfg.ReadTimeout)
+	}
+
+	server := &Server{
+		Echo:    e,
+		DB:      db,
+		Hub:     NewHub(),
+		Policy:  policyFromConfig(cfg),
+		APIKeys: loadAPIKeys(cfg.APIKeys, logger),
+		Counter: &EventCounter{},
+
+		logger:            logger,
+		reconcileInterval: time.Duration(cfg.CountReconcileInterval),
+		retention:         time.Duration(cfg.EventRetention),
+		cleanupInterval:   time.Duration(cfg.CleanupInterval),
+		pprof:             cfg.EnablePprof,
+		shutdownTimeout:   time.Duration(cfg.ShutdownTimeout),
+		readOnly:          cfg.ReadOnly,
+		basePath:          cfg.BasePath,
+		backupPath:        cfg.BackupPath,
+		queryBudget:       cfg.QueryBudget,
+		devMode:           cfg.DevMode,
+		grpcPort:          cfg.GRPCPort,
+		cacheControl:      cfg.CacheControl,
+		maxRangeSpan:      time.Duration(cfg.MaxRangeSpan),
+		rules:             rules,
+	}
+	if cfg.MaxExpensiveRequests > 0 {
+		server.expensive = make(chan struct{}, cfg.MaxExpensiveRequests)
+	}
+	if cfg.DedupWindow > 0 {
+		server.dedup = NewDeduplicator(time.Duration(cfg.DedupWindow))
+	}
 
 	// Middlewarego
+	e.Use(traceRequests)
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	e.Use(server.allowMethods)
+	if cfg.WriteTimeout > 0 {
+		e.Use(server.limitWriteTime(time.Duration(cfg.WriteTimeout)))
+	}
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		// Let browser clients read the pagination and caching headers
+		AllowOrigins:  cfg.CORSAllowOrigins,
+		ExposeHeaders: []string{"Link", HeaderTotalCount, "ETag", echo.HeaderLocation, HeaderPreferenceApplied, HeaderQueryCount, HeaderClientID},
+	}))
+	if cfg.GzipLevel > 0 {
+		e.Use(server.compressResponses(cfg.GzipLevel, cfg.GzipMinLength))
+	}
+	e.Use(server.countQueries)
+	e.Use(server.setCacheControl)
 
-	server := &Server{
-		Echo: e,
-		DB:   db,
+	// Seed the cached event count
+	if err := server.reconcileCount(context.Background()); err != nil {
+		server.logger.Error("Error loading event count", "error", err)
 	}
 
+	e.HTTPErrorHandler = server.errorHandler
+
 	// Register routes
 	server.registerRoutes()
+	server.checkCacheRoutes()
 
 	return server
 }
@@ -43,7 +153,30 @@ func NewServer(db *repository.Database) *Server {
 // Accepts a JSON payload with title, description, start_time, and end_time
 // Returns the created event as JSON with HTTP 201 status
 func (s *Server) createEvent(c echo.Context) error {
-	ctx := context.Background()
+	ctx := c.Request().Context()
+
+	if c.QueryParam("validate_only") == "true" {
+		return s.validateEvent(c)
+	}
+
+	if err := validateClientID(c); err != nil {
+		return err
+	}
+
+	// Events matching an existing one on these fields aren't created again
+	uniqueOn, err := models.ParseUniqueOn(c.QueryParam("unique_on"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	idempotencyKey := c.Request().Header.Get(HeaderIdempotencyKey)
+	if idempotencyKey != "" && len(uniqueOn) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "unique_on can't be combined with an Idempotency-Key",
+		})
+	}
 
 	// Parse request body
 	var req models.CreateEventRequest
@@ -53,54 +186,403 @@ func (s *Server) createEvent(c echo.Context) error {
 		})
 	}
 
-	// Validate request
-	if err := models.IsValid(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+	// Replay the original response if this idempotency key was already used
+	var idempotent *idempotentRequest
+	if idempotencyKey != "" {
+		idempotent = newIdempotentRequest(c, idempotencyKey, uuid.Nil, req)
+		replayed, err := s.replayCreatedEvent(c, idempotent)
+		if replayed || err != nil {
+			return err
+		}
 	}
 
-	// Parse timestamps
-	startTime, _ := utils.ParseTimestamp(req.StartTime)
-	endTime, _ := utils.ParseTimestamp(req.EndTime)
+	// Fill in omitted fields before validating
+	s.applyDefaults(&req)
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
 
 	// Create event object
 	event := &models.Event{
-		Title:       req.Title,
-		Description: req.Description,
-		StartTime:   startTime,
-		EndTime:     endTime,
+		CreatedBy: principalID(c),
+	}
+	req.ApplyTo(event)
+
+	// Clients creating events offline choose the ID themselves
+	if req.ID != "" {
+		event.ID = uuid.MustParse(req.ID)
+	}
+
+	// An existing match is returned before the booking policy, which it already passed,
+	// and which is checked in the insert's transaction
+	if len(uniqueOn) > 0 {
+		match, err := s.DB.FindMatchingEvent(ctx, event, uniqueOn)
+		if err == nil {
+			return s.respondCreated(c, http.StatusOK, match)
+		}
+		if !errors.Is(err, repository.ErrEventNotFound) {
+			s.logger.Error("Error finding matching event", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create event",
+			})
+		}
 	}
 
-	// Insert into database (ID and CreatedAt will be generated automatically)
-	if err := s.DB.InsertEvent(ctx, event); err != nil {
-		log.Printf("Error inserting event: %v", err)
+	// Insert into database (ID and CreatedAt will be generated automatically), enforcing
+	// the booking policy in the same transaction
+	check := s.policyCheck(event, principal(c))
+	if idempotent != nil {
+		err = s.DB.InsertEventWithIdempotencyKey(ctx, event, idempotent.scope, idempotent.hash, check)
+	} else if len(uniqueOn) > 0 {
+		// Check again in the insert's transaction in case a concurrent request created it
+		match, created, insertErr := s.DB.InsertEventUnlessExists(ctx, event, uniqueOn, check)
+		if insertErr == nil && !created {
+			return s.respondCreated(c, http.StatusOK, match)
+		}
+		err = insertErr
+	} else {
+		err = s.DB.InsertEvent(ctx, event, check)
+	}
+	if errors.Is(err, ErrWindowLimitExceeded) || errors.Is(err, ErrOwnerLimitExceeded) {
+		return s.policyError(c, err, "Failed to create event")
+	}
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		// A concurrent request with the same key won the race
+		replayed, err := s.replayCreatedEvent(c, idempotent)
+		if replayed || err != nil {
+			return err
+		}
+		s.logger.Error("Error getting idempotency key: not found after a concurrent create stored it", "key", idempotencyKey)
 		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to create event",
 		})
 	}
+	if errors.Is(err, repository.ErrEventExists) {
+		return echo.NewHTTPError(http.StatusConflict, map[string]string{
+			"error": "An event with this ID already exists",
+		})
+	}
+	if err != nil {
+		s.logger.Error("Error inserting event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create event",
+		})
+	}
+
+	s.Counter.Add(1)
+	s.Hub.Publish(EventChange{Type: ChangeCreated, Event: event})
 
 	// Return created event with 201 status
-	return c.JSON(http.StatusCreated, event)
+	return s.respondCreated(c, http.StatusCreated, event)
+}
+
+// validateEvent handles POST /events?validate_only=true
+// Runs the same binding, validation and booking policy checks as creation without inserting,
+// returning 200 with {"valid": true} or 422 with the errors
+func (s *Server) validateEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.CreateEventRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	s.applyDefaults(&req)
+
+	invalid := func(err error) error {
+		lang := requestLanguage(c)
+		c.Response().Header().Set("Content-Language", lang)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+			"valid":  false,
+			"errors": localizeFieldErrors(lang, toFieldErrors(err)),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return invalid(err)
+	}
+
+	event := &models.Event{
+		CreatedBy: principalID(c),
+	}
+	req.ApplyTo(event)
+
+	if err := s.checkPolicy(ctx, s.DB, event, principal(c)); err != nil {
+		if errors.Is(err, ErrWindowLimitExceeded) || errors.Is(err, ErrOwnerLimitExceeded) {
+			return invalid(err)
+		}
+		s.logger.Error("Error checking booking policy", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to validate event",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]bool{
+		"valid": true,
+	})
+}
+
+// idempotentRequest identifies a create or update sent with an idempotency key
+type idempotentRequest struct {
+	scope repository.IdempotencyScope
+	hash  string
+}
+
+// newIdempotentRequest scopes key to the caller and method of the request and fingerprints
+// the request, id being uuid.Nil for a creation
+func newIdempotentRequest(c echo.Context, key string, id uuid.UUID, req models.CreateEventRequest) *idempotentRequest {
+	scope := repository.IdempotencyScope{Method: c.Request().Method, Key: key}
+	if p := principal(c); p != nil {
+		scope.Principal = p.ID
+	}
+	return &idempotentRequest{scope: scope, hash: requestHash(id, req)}
+}
+
+// requestHash fingerprints a creation or full update of an event, so a key reused for
+// a different request can be told apart from a retry
+func requestHash(id uuid.UUID, req models.CreateEventRequest) string {
+	// The request was just decoded from JSON, so encoding it back can't fail
+	body, _ := json.Marshal(req)
+
+	hash := sha256.New()
+	hash.Write([]byte(id.String()))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// findIdempotentRequest looks up the event stored with the request's idempotency key
+// It reports false when the key is unknown, so the request should go ahead, and answers
+// 422 when the key was used for a different request.
+func (s *Server) findIdempotentRequest(c echo.Context, idempotent *idempotentRequest, failure string) (uuid.UUID, bool, error) {
+	eventID, hash, err := s.DB.GetIdempotencyKey(c.Request().Context(), idempotent.scope)
+	if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		s.logger.Error("Error getting idempotency key", "error", err)
+		return uuid.Nil, true, echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": failure,
+		})
+	}
+
+	if hash != idempotent.hash {
+		return uuid.Nil, true, echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]string{
+			"error": "Idempotency-Key was already used for a different request",
+		})
+	}
+	return eventID, true, nil
+}
+
+// replayCreatedEvent answers a retry of a creation whose idempotency key was already used,
+// returning the event previously created with 200 status instead of inserting it again
+// It reports false when the key is unknown, so the event should be created.
+func (s *Server) replayCreatedEvent(c echo.Context, idempotent *idempotentRequest) (bool, error) {
+	id, found, err := s.findIdempotentRequest(c, idempotent, "Failed to create event")
+	if !found || err != nil {
+		return found, err
+	}
+
+	event, err := s.DB.GetEventByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return true, echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return true, echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	return true, s.respondCreated(c, http.StatusOK, event)
+}
+
+// replayUpdatedEvent answers a retry of an update whose idempotency key was already used,
+// returning the event with 200 status instead of applying the update again
+// It reports false when the key is unknown, so the update should go ahead.
+func (s *Server) replayUpdatedEvent(c echo.Context, id uuid.UUID, idempotent *idempotentRequest) (bool, error) {
+	ctx := c.Request().Context()
+
+	// The fingerprint covers the event ID, so a key used for another event doesn't match
+	if _, found, err := s.findIdempotentRequest(c, idempotent, "Failed to update event"); !found || err != nil {
+		return found, err
+	}
+
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return true, echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return true, echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	c.Response().Header().Set("ETag", eventETag(event))
+	return true, s.respondEvent(c, http.StatusOK, event)
 }
 
-// registerRoutes sets up all the API routes
+// registerRoutes sets up all the API routes under the base path
+// Links the server builds, like Location and pagination, follow the request path and so
+// include the base path too
 func (s *Server) registerRoutes() {
+	root := s.Echo.Group(s.basePath)
+	root.GET("/metrics", s.metrics)
+
+	// Operator endpoints
+	debug := root.Group("/debug", s.authenticate, requireAdmin)
+	debug.GET("/stats", s.debugStats)
+	if s.pprof {
+		s.logger.Info("Profiling endpoints enabled", "path", s.basePath+"/debug/pprof")
+		if len(s.APIKeys) == 0 {
+			s.logger.Warn("Profiling endpoints refuse every request until API_KEYS has an admin key")
+		}
+		registerPprof(debug)
+	}
+
 	// API v1 routes
-	api := s.Echo.Group("/api/v1")
-	api.POST("/events", s.createEvent)
+	api := root.Group("/api/v1", s.authenticate, s.rejectWrites)
+	api.GET("/version", s.getVersion)
+	api.GET("/tags", s.listTags)
+	api.POST("/events", s.createEvent, s.dedupRequests)
 	api.GET("/events", s.listEvents)
+	api.GET("/events/count", s.countEvents)
+	api.GET("/events/stats", s.getEventStats)
+	api.GET("/events/next", s.getNextEvent)
+	api.GET("/events/availability", s.checkAvailability)
+	api.GET("/events/titles", s.listEventTitles)
+	api.GET("/events/stream", s.streamEvents)
+	api.GET("/events/by-day", s.listEventsByDay)
+	api.GET("/events/today", s.listEventsToday)
+	api.GET("/events/fullcalendar", s.listFullCalendarEvents)
+	api.GET("/events/changes", s.listChanges)
+	api.GET("/events/export", s.exportEvents, s.limitExpensive)
+	api.GET("/events/export.ics", s.exportCalendar, s.limitExpensive)
+	api.POST("/events/restore", s.restoreEvents, requireAdmin)
+	api.POST("/events/import", s.importEvents, requireAdmin, s.limitExpensive)
+	api.POST("/events/batch-get", s.batchGetEvents, s.limitExpensive)
+	api.POST("/events/shift", s.shiftEvents, s.limitExpensive)
 	api.GET("/events/:id", s.getEventByID)
+	api.GET("/events/:id/history", s.getEventHistory)
+	api.GET("/events/:id/adjacent", s.getAdjacentEvents)
+	api.GET("/events/:id/conflicts", s.getEventConflicts)
+	api.GET("/events/:id/occurrences", s.listOccurrences)
+	api.POST("/events/:id/reschedule", s.rescheduleEvent)
+	api.POST("/events/:id/clone", s.cloneEvent)
+	api.PUT("/events/:id", s.updateEvent)
+	api.PATCH("/events/:id", s.patchEvent)
+	api.DELETE("/events/:id", s.deleteEvent)
+	api.POST("/maintenance/vacuum", s.vacuumDatabase, requireAdmin)
+	api.POST("/maintenance/analyze", s.analyzeDatabase, requireAdmin)
+	api.POST("/maintenance/backup", s.backupDatabase, requireAdmin)
+
+	s.registerOptions()
 }
 
 // listEvents handles GET /events
 // Returns a JSON array of all events ordered by start_time ascending
 func (s *Server) listEvents(c echo.Context) error {
-	ctx := context.Background()
+	ctx := c.Request().Context()
 
-	events, err := s.DB.GetAllEvents(ctx)
+	// Parse optional sparse fieldset
+	fields, err := models.ParseFields(c.QueryParam("fields"))
 	if err != nil {
-		log.Printf("Error getting events: %v", err)
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	filter := models.EventFilter{
+		Owner:  c.QueryParam("owner"),
+		Status: c.QueryParam("status"),
+		Tags:   c.QueryParams()["tag"],
+	}
+
+	if filter.Sort, err = models.ParseSortField(c.QueryParam("sort")); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if filter.Order, err = models.ParseSortOrder(c.QueryParam("order")); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if filter.Status != "" && !models.IsValidStatus(filter.Status) {
+		return validationError(c, &models.InvalidStatus)
+	}
+
+	if filter.CreatedFrom, err = s.parseOptionalTime(c, "created_from"); err != nil {
+		return err
+	}
+	if filter.CreatedTo, err = s.parseOptionalTime(c, "created_to"); err != nil {
+		return err
+	}
+
+	if filter.MinDuration, err = parseOptionalDuration(c, "min_duration"); err != nil {
+		return err
+	}
+	if filter.MaxDuration, err = parseOptionalDuration(c, "max_duration"); err != nil {
+		return err
+	}
+	if filter.MaxDuration != 0 && filter.MinDuration > filter.MaxDuration {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "min_duration should not be greater than max_duration",
+		})
+	}
+
+	for param, values := range c.QueryParams() {
+		key, ok := strings.CutPrefix(param, models.MetadataFilterPrefix)
+		if !ok {
+			continue
+		}
+		if !models.IsValidMetadataKey(key) {
+			return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": "Invalid metadata filter key, expected a simple identifier",
+			})
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]string)
+		}
+		filter.Metadata[key] = values[0]
+	}
+
+	if filter.Limit, filter.Offset, err = parsePagination(c); err != nil {
+		return err
+	}
+
+	// Let polling clients skip the payload when nothing matching the filter changed
+	total, maxUpdated, err := s.DB.GetEventsVersion(ctx, filter)
+	if err != nil {
+		s.logger.Error("Error getting events version", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	etag := weakETag(total, maxUpdated)
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	if filter.Limit > 0 {
+		setPageLinks(c, filter.Limit, filter.Offset, total)
+	}
+	if etagMatches(c, etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	events, err := s.DB.GetAllEvents(ctx, filter)
+	if err != nil {
+		s.logger.Error("Error getting events", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to retrieve events",
 		})
@@ -111,13 +593,57 @@ func (s *Server) listEvents(c echo.Context) error {
 		events = []*models.Event{}
 	}
 
+	if wantsProtobuf(c) {
+		message, err := marshalEventList(events, total)
+		return s.respondProtobuf(c, http.StatusOK, message, err)
+	}
+	if wantsJSONAPI(c) {
+		return s.respondEventList(c, events, fields, filter.Limit, filter.Offset, total)
+	}
+
+	if len(fields) > 0 {
+		selected := make([]map[string]interface{}, 0, len(events))
+		for _, event := range events {
+			item, err := models.SelectFields(event, fields)
+			if err != nil {
+				s.logger.Error("Error selecting event fields", "error", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+					"error": "Failed to retrieve events",
+				})
+			}
+			selected = append(selected, item)
+		}
+		return c.JSON(http.StatusOK, selected)
+	}
+
 	return c.JSON(http.StatusOK, events)
 }
 
+// listEventTitles handles GET /events/titles
+// Returns the id, title and start_time of every event ordered by start_time
+func (s *Server) listEventTitles(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	titles, err := s.DB.GetEventTitles(ctx)
+	if err != nil {
+		s.logger.Error("Error getting event titles", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	// Return empty array instead of null if no events
+	if titles == nil {
+		titles = []models.EventTitle{}
+	}
+
+	return c.JSON(http.StatusOK, titles)
+}
+
 // getEventByID handles GET /events/:id
 // Returns the event with the specified UUID or 404 if not found
 func (s *Server) getEventByID(c echo.Context) error {
-	ctx := context.Background()
+	ctx := c.Request().Context()
 
 	// Parse UUID from path parameter
 	idParam := c.Param("id")
@@ -131,12 +657,81 @@ func (s *Server) getEventByID(c echo.Context) error {
 	// Get event from database
 	event, err := s.DB.GetEventByID(ctx, id)
 	if err != nil {
-		if err.Error() == "event not found" {
+		if errors.Is(err, repository.ErrEventNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
 				"error": "Event not found",
 			})
 		}
-		log.Printf("Error getting event by ID: %v", err)
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	c.Response().Header().Set("ETag", eventETag(event))
+	return s.respondEvent(c, http.StatusOK, event)
+}
+
+// getAdjacentEvents handles GET /events/:id/adjacent
+// Returns the events right before and after the given one by start time, for previous and
+// next navigation, with null at the ends of the list
+func (s *Server) getAdjacentEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	previous, next, err := s.DB.GetAdjacentEvents(ctx, event)
+	if err != nil {
+		s.logger.Error("Error getting adjacent events", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.AdjacentEvents{
+		Previous: previous,
+		Next:     next,
+	})
+}
+
+// getNextEvent handles GET /events/next
+// Returns the earliest upcoming event with the given title (case-insensitive) or 404 if none
+func (s *Server) getNextEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	title := strings.TrimSpace(c.QueryParam("title"))
+	if title == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "title is required",
+		})
+	}
+
+	event, err := s.DB.GetNextEventByTitle(ctx, title, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "No upcoming event with that title",
+			})
+		}
+		s.logger.Error("Error getting next event", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to retrieve event",
 		})
@@ -145,7 +740,341 @@ func (s *Server) getEventByID(c echo.Context) error {
 	return c.JSON(http.StatusOK, event)
 }
 
-// Start starts the HTTP server
-func (s *Server) Start(port string) error {
-	return s.Echo.Start(":" + port)
+// updateEvent handles PUT /events/:id
+// Replaces the title, description, start_time, end_time and status of an existing event
+// Returns the updated event or 404 if not found. With an Idempotency-Key a retry of the same
+// request returns the event instead of failing its If-Match check against its own update.
+func (s *Server) updateEvent(c echo.Context) error {
+	// Parse UUID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	// Parse request body
+	var req models.CreateEventRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	var idempotent *idempotentRequest
+	if key := c.Request().Header.Get(HeaderIdempotencyKey); key != "" {
+		idempotent = newIdempotentRequest(c, key, id, req)
+	}
+
+	return s.saveEvent(c, id, idempotent, func(*models.Event) models.CreateEventRequest {
+		// Fill in omitted fields before validating
+		s.applyDefaults(&req)
+		return req
+	})
+}
+
+// patchEvent handles PATCH /events/:id
+// Changes only the fields present in the payload, e.g. {"status": "cancelled"} to cancel an event
+// Returns the updated event or 404 if not found
+func (s *Server) patchEvent(c echo.Context) error {
+	// Parse UUID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	// Parse request body
+	// Echo's binder doesn't know the merge patch media type, so decode it directly
+	var patch models.PatchEventRequest
+	var bindErr error
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), MIMEMergePatchJSON) {
+		patch.MergePatch = true
+		bindErr = json.NewDecoder(c.Request().Body).Decode(&patch)
+	} else {
+		bindErr = c.Bind(&patch)
+	}
+	if bindErr != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	return s.saveEvent(c, id, nil, patch.Merge)
+}
+
+// saveEvent loads an event, builds its new content from the current state, validates
+// and stores it. It backs both full (PUT) and partial (PATCH) updates. With If-Match only
+// the version the client last read is updated; idempotent, when not nil, identifies the
+// request so its retries are answered with the event instead of being applied again.
+func (s *Server) saveEvent(c echo.Context, id uuid.UUID, idempotent *idempotentRequest, build func(current *models.Event) models.CreateEventRequest) error {
+	ctx := c.Request().Context()
+
+	// Load the current event to check ownership
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	if err := authorizeWrite(c, event); err != nil {
+		return err
+	}
+
+	if idempotent != nil {
+		replayed, err := s.replayUpdatedEvent(c, id, idempotent)
+		if replayed || err != nil {
+			return err
+		}
+	}
+
+	// Validate the new content
+	req := build(event)
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// Keep the state before the update for return=changed
+	previous := *event
+	req.ApplyTo(event)
+
+	if idempotent != nil {
+		err = s.DB.UpdateEventWithIdempotencyKey(ctx, event, ifMatchPrecondition(c), idempotent.scope, idempotent.hash)
+	} else {
+		err = s.DB.UpdateEvent(ctx, event, ifMatchPrecondition(c))
+	}
+	if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+		// A concurrent request with the same key won the race
+		replayed, err := s.replayUpdatedEvent(c, id, idempotent)
+		if replayed || err != nil {
+			return err
+		}
+		s.logger.Error("Error getting idempotency key: not found after a concurrent update stored it", "key", idempotent.scope.Key)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to update event",
+		})
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		if errors.Is(err, repository.ErrPreconditionFailed) {
+			return echo.NewHTTPError(http.StatusPreconditionFailed, map[string]string{
+				"error": "The event changed since it was read, fetch it again before updating",
+			})
+		}
+		s.logger.Error("Error updating event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to update event",
+		})
+	}
+
+	// Reload to return the stored representation including created_at
+	updated, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error getting updated event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	s.Hub.Publish(EventChange{Type: ChangeUpdated, Event: updated})
+
+	return s.respondUpdated(c, &previous, updated)
+}
+
+// deleteEvent handles DELETE /events/:id
+// Returns 204 on success or 404 if not found
+func (s *Server) deleteEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Parse UUID from path parameter
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	// Load the event first to check ownership and so subscribers receive what was deleted
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err == nil {
+		if err := authorizeWrite(c, event); err != nil {
+			return err
+		}
+		// With If-Match only the version the client last read may be deleted
+		err = s.DB.DeleteEvent(ctx, id, ifMatchPrecondition(c))
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		if errors.Is(err, repository.ErrPreconditionFailed) {
+			return echo.NewHTTPError(http.StatusPreconditionFailed, map[string]string{
+				"error": "The event changed since it was read, fetch it again before deleting",
+			})
+		}
+		s.logger.Error("Error deleting event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to delete event",
+		})
+	}
+
+	s.Counter.Add(-1)
+	s.Hub.Publish(EventChange{Type: ChangeDeleted, Event: event})
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// parseBatchIDs parses and deduplicates the IDs of a batch request, keeping their order
+func parseBatchIDs(raw []string) ([]uuid.UUID, error) {
+	if len(raw) > models.MaxBatchSize {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("at most %d ids can be requested at once", models.MaxBatchSize),
+		})
+	}
+
+	ids := make([]uuid.UUID, 0, len(raw))
+	seen := make(map[uuid.UUID]bool, len(raw))
+	for _, idStr := range raw {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("Invalid UUID format: %q", idStr),
+			})
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Start starts the HTTP server and its background jobs, and shuts them down
+// gracefully once ctx is cancelled
+func (s *Server) Start(ctx context.Context, port string) error {
+	return s.serve(ctx, func() error {
+		return s.Echo.Start(":" + port)
+	})
+}
+
+// StartTLS is like Start but serves HTTPS, and HTTP/2 to clients supporting it,
+// with the PEM encoded certificate and key files
+func (s *Server) StartTLS(ctx context.Context, port, certFile, keyFile string) error {
+	return s.serve(ctx, func() error {
+		return s.Echo.StartTLS(":"+port, certFile, keyFile)
+	})
+}
+
+// serve runs the background jobs and listen, and once ctx is cancelled shuts down
+// every listener gracefully
+func (s *Server) serve(ctx context.Context, listen func() error) error {
+	go s.runReconciler(ctx, s.reconcileInterval)
+	if s.readOnly {
+		s.logger.Info("Read-only mode, writes are rejected")
+	} else if s.retention > 0 {
+		go s.runCleanup(ctx, s.cleanupInterval)
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- listen()
+	}()
+
+	var grpcServer *grpc.Server
+	if s.grpcPort != "" {
+		grpcServer = s.newGRPCServer()
+		go func() {
+			errs <- s.serveGRPC(grpcServer, s.grpcPort)
+		}()
+	}
+
+	select {
+	case err := <-errs:
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	s.logger.Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if grpcServer != nil {
+		// Let in-flight calls finish, cutting them off once the timeout expires
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if err := s.Echo.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
+}
+
+// batchGetEvents handles POST /events/batch-get
+// Returns the events matching up to MaxBatchSize IDs plus the IDs that weren't found
+func (s *Server) batchGetEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.BatchGetRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	ids, err := parseBatchIDs(req.IDs)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.DB.GetEventsByIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error("Error getting events by IDs", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+
+	found := make(map[uuid.UUID]bool, len(events))
+	for _, event := range events {
+		found[event.ID] = true
+	}
+
+	resp := models.BatchGetResponse{
+		Events:  events,
+		Missing: []uuid.UUID{},
+	}
+	if resp.Events == nil {
+		resp.Events = []*models.Event{}
+	}
+	for _, id := range ids {
+		if !found[id] {
+			resp.Missing = append(resp.Missing, id)
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"challenge/config"
+	"challenge/models"
+	"challenge/repository"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// newTestServer creates a server backed by a fresh database in a temporary directory
+// configure adjusts the default configuration before the server is built
+func newTestServer(t *testing.T, configure func(*config.Config)) *Server {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.DBPath = filepath.Join(t.TempDir(), "events.db")
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := repository.NewDatabase(context.Background(), cfg, logger)
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	t.Cleanup(db.Close)
+	if err := db.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable: %v", err)
+	}
+	return NewServer(db, cfg, logger)
+}
+
+// do sends a JSON request to the server and decodes the response body into out, if not nil
+func do(t *testing.T, s *Server, method, path, body string, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return doWithKey(t, s, "", method, path, body, out)
+}
+
+// doWithKey is do authenticating with the API key, unless empty
+func doWithKey(t *testing.T, s *Server, key, method, path, body string, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderAPIKey, key)
+	}
+	rec := httptest.NewRecorder()
+	s.Echo.ServeHTTP(rec, req)
+
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func TestPatchEventKeepsOmittedPriority(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	var created models.Event
+	rec := do(t, s, http.MethodPost, "/api/v1/events",
+		`{"title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z","priority":5}`, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var patched models.Event
+	rec = do(t, s, http.MethodPatch, "/api/v1/events/"+created.ID.String(), `{"title":"Planning v2"}`, &patched)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: status %d: %s", rec.Code, rec.Body)
+	}
+	if patched.Title != "Planning v2" {
+		t.Errorf("title = %q, want %q", patched.Title, "Planning v2")
+	}
+	if patched.Priority != 5 {
+		t.Errorf("priority = %d, want 5 to be kept", patched.Priority)
+	}
+
+	rec = do(t, s, http.MethodPatch, "/api/v1/events/"+created.ID.String(), `{"priority":2}`, &patched)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: status %d: %s", rec.Code, rec.Body)
+	}
+	if patched.Priority != 2 {
+		t.Errorf("priority = %d, want 2", patched.Priority)
+	}
+}
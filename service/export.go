@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bufio"
+	"challenge/models"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// exportETag builds the strong entity tag of an export from the number of events and their
+// latest update, distinguishing the gzip encoding since its bytes differ
+func exportETag(count int, maxUpdated time.Time, encoding string) string {
+	tag := strings.Trim(strings.TrimPrefix(weakETag(count, maxUpdated), "W/"), `"`)
+	if encoding != "" {
+		tag += "-" + encoding
+	}
+	return `"` + tag + `"`
+}
+
+// exportEvents handles GET /events/export
+// Streams every event as a JSON array, gzip compressed when the client accepts it
+// A single byte Range is honored on the uncompressed export so interrupted downloads can resume
+func (s *Server) exportEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	count, maxUpdated, err := s.DB.GetEventsVersion(ctx, models.EventFilter{})
+	if err != nil {
+		s.logger.Error("Error getting events version", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to export events",
+		})
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	w.Header().Set(echo.HeaderContentDisposition, `attachment; filename="events.json"`)
+	w.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	req := c.Request()
+	if r, ok := parseByteRange(req.Header.Get("Range")); ok {
+		etag := exportETag(count, maxUpdated, "")
+		// A resumed download must come from the same export, otherwise send it whole
+		if ifRange := req.Header.Get("If-Range"); ifRange == "" || ifRange == etag {
+			w.Header().Set("ETag", etag)
+			return s.exportRange(c, r)
+		}
+	}
+
+	var out io.Writer = w
+	if acceptsGzip(req.Header.Get(echo.HeaderAcceptEncoding)) {
+		w.Header().Set(echo.HeaderContentEncoding, "gzip")
+		w.Header().Set("ETag", exportETag(count, maxUpdated, "gzip"))
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	} else {
+		w.Header().Set("ETag", exportETag(count, maxUpdated, ""))
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := s.writeExport(ctx, out); err != nil {
+		// Headers are already sent, so the truncated body is all the client gets
+		s.logger.Error("Error exporting events", "error", err)
+	}
+	return nil
+}
+
+// exportRange writes the requested byte range of the uncompressed export as 206 Partial Content
+// The export is spooled to a temporary file once, so the declared length always matches the
+// bytes sent even if events change meanwhile, and the range is served from it
+func (s *Server) exportRange(c echo.Context, r byteRange) error {
+	ctx := c.Request().Context()
+
+	spool, err := os.CreateTemp("", "events-export-*.json")
+	if err != nil {
+		s.logger.Error("Error creating export spool file", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to export events",
+		})
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	out := bufio.NewWriter(spool)
+	err = s.writeExport(ctx, out)
+	if err == nil {
+		err = out.Flush()
+	}
+	var info os.FileInfo
+	if err == nil {
+		info, err = spool.Stat()
+	}
+	if err != nil {
+		s.logger.Error("Error spooling export", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to export events",
+		})
+	}
+	size := info.Size()
+
+	w := c.Response()
+	start, end, ok := r.resolve(size)
+	if !ok {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		return echo.NewHTTPError(http.StatusRequestedRangeNotSatisfiable, map[string]string{
+			"error": "Requested range not satisfiable",
+		})
+	}
+
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	w.Header().Set(echo.HeaderContentLength, strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(http.StatusPartialContent)
+
+	if _, err := io.Copy(w, io.NewSectionReader(spool, start, end-start+1)); err != nil {
+		s.logger.Error("Error exporting events", "error", err)
+	}
+	return nil
+}
+
+// writeExport writes every event to out as a JSON array
+// The output only depends on the stored events, so equal data always gives equal bytes
+func (s *Server) writeExport(ctx context.Context, out io.Writer) error {
+	if _, err := io.WriteString(out, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := s.DB.StreamEvents(ctx, func(event *models.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(out, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = out.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, "]")
+	return err
+}
+
+// restoreEvents handles POST /events/restore
+// Accepts a JSON array produced by the export endpoint and upserts the events,
+// preserving their IDs and created_at, so running the same restore twice is harmless
+func (s *Server) restoreEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var events []*models.Event
+	if err := c.Bind(&events); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	for i, event := range events {
+		if event == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("event %d: invalid event", i),
+			})
+		}
+		// Exports taken before statuses existed have none
+		if event.Status == "" {
+			event.Status = models.StatusConfirmed
+		}
+		if err := models.IsValidEvent(event, s.rules); err != nil {
+			return itemValidationError(c, i, err)
+		}
+	}
+
+	if err := s.DB.RestoreEvents(ctx, events); err != nil {
+		s.logger.Error("Error restoring events", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to restore events",
+		})
+	}
+
+	// Restored events may replace existing ones, so recount instead of adding
+	if err := s.reconcileCount(ctx); err != nil {
+		s.logger.Error("Error reconciling event count", "error", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{
+		"restored": len(events),
+	})
+}
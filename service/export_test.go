@@ -0,0 +1,39 @@
+package service
+
+import (
+	"challenge/config"
+	"net/http"
+	"testing"
+)
+
+func TestRestoreRejectsWhatCreateRejects(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"admin-key:alice:admin"}
+		cfg.AllowZeroDuration = false
+	})
+
+	event := func(title, start, end string) string {
+		return `[{"id":"7c9e6679-7425-40de-944b-e07fc1f99a43","title":"` + title + `","start_time":"` + start +
+			`","end_time":"` + end + `","created_at":"2025-01-01T00:00:00Z","status":"confirmed"}]`
+	}
+	tests := []struct {
+		name, body string
+	}{
+		{"NUL in the title", event(`Planning\u0000`, "2025-03-01T10:00:00Z", "2025-03-01T11:00:00Z")},
+		{"escape in the title", event(`\u001b[2JPlanning`, "2025-03-01T10:00:00Z", "2025-03-01T11:00:00Z")},
+		{"year out of range", event("Planning", "2200-03-01T10:00:00Z", "2200-03-01T11:00:00Z")},
+		{"zero duration", event("Planning", "2025-03-01T10:00:00Z", "2025-03-01T10:00:00Z")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doWithKey(t, s, "admin-key", http.MethodPost, "/api/v1/events/restore", tt.body, nil); rec.Code != http.StatusBadRequest {
+				t.Errorf("restore: status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+		})
+	}
+
+	valid := event("Planning", "2025-03-01T10:00:00Z", "2025-03-01T11:00:00Z")
+	if rec := doWithKey(t, s, "admin-key", http.MethodPost, "/api/v1/events/restore", valid, nil); rec.Code != http.StatusOK {
+		t.Errorf("restoring a valid event: status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
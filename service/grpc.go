@@ -0,0 +1,383 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcPrincipalKey is the context key holding the *Principal of a gRPC call
+type grpcPrincipalKey struct{}
+
+// grpcPrincipal returns the authenticated caller of a gRPC call, nil when authentication is disabled
+func grpcPrincipal(ctx context.Context) *Principal {
+	p, _ := ctx.Value(grpcPrincipalKey{}).(*Principal)
+	return p
+}
+
+// grpcEvents implements the gRPC EventService of proto/events.proto with the same store,
+// validation, booking policy and ownership rules as the REST handlers
+type grpcEvents struct {
+	s     *Server
+	store repository.EventStore
+}
+
+// newGRPCServer creates the gRPC server with the event service and server reflection,
+// so tools like grpcurl can discover the schema
+func (s *Server) newGRPCServer() *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(s.grpcAuthenticate))
+
+	events := &grpcEvents{s: s, store: s.DB}
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: grpcServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			events.method("CreateEvent", events.createEvent),
+			events.method("GetEvent", events.getEvent),
+			events.method("ListEvents", events.listEvents),
+			events.method("UpdateEvent", events.updateEvent),
+			events.method("DeleteEvent", events.deleteEvent),
+		},
+		Metadata: eventsSchema.Path(),
+	}, events)
+	reflection.Register(srv)
+
+	return srv
+}
+
+// serveGRPC listens for gRPC calls on port until srv is stopped
+func (s *Server) serveGRPC(srv *grpc.Server, port string) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+	s.logger.Info("gRPC server starting", "port", port)
+	return srv.Serve(listener)
+}
+
+// grpcAuthenticate is a unary interceptor resolving the caller from the x-api-key or
+// authorization metadata, like the authenticate middleware does from headers
+func (s *Server) grpcAuthenticate(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if len(s.APIKeys) == 0 {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	key := firstValue(md, strings.ToLower(HeaderAPIKey))
+	if key == "" {
+		key = strings.TrimPrefix(firstValue(md, "authorization"), "Bearer ")
+	}
+
+	p, ok := s.APIKeys[key]
+	if key == "" || !ok {
+		return nil, status.Error(codes.Unauthenticated, "Invalid or missing API key")
+	}
+
+	ctx = context.WithValue(ctx, grpcPrincipalKey{}, p)
+	// Attribute the call's changes to the caller in the audit log
+	return handler(repository.WithActor(ctx, p.ID), req)
+}
+
+// firstValue returns the first value of a metadata key, empty when it isn't set
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// method describes a unary RPC decoding its input as the message the schema declares
+func (g *grpcEvents) method(name string, call func(context.Context, *dynamicpb.Message) (proto.Message, error)) grpc.MethodDesc {
+	input := eventsSchema.Services().ByName("EventService").Methods().ByName(protoreflect.Name(name)).Input()
+	info := &grpc.UnaryServerInfo{Server: g, FullMethod: "/" + grpcServiceName + "/" + name}
+
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := dynamicpb.NewMessage(input)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(ctx, req.(*dynamicpb.Message))
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+// decodeMessage decodes a request message into the matching REST payload type through
+// protojson, whose field names are the payload's JSON names
+func decodeMessage(message proto.Message, v interface{}) error {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return status.Error(codes.InvalidArgument, "Invalid request payload")
+	}
+	return nil
+}
+
+// encodeMessage decodes the protobuf encoding of an event or list into the named schema message
+func encodeMessage(name protoreflect.Name, data []byte, err error) (proto.Message, error) {
+	if err != nil {
+		return nil, err
+	}
+	message := dynamicpb.NewMessage(schemaMessage(name))
+	if err := proto.Unmarshal(data, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// eventMessage converts an event to the Event message
+func eventMessage(event *models.Event) (proto.Message, error) {
+	data, err := marshalEvent(event)
+	return encodeMessage("Event", data, err)
+}
+
+// parseEventID parses the id field of a request
+func parseEventID(in *dynamicpb.Message) (uuid.UUID, error) {
+	id, err := uuid.Parse(in.Get(in.Descriptor().Fields().ByName("id")).String())
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "Invalid UUID format")
+	}
+	return id, nil
+}
+
+// rejectWrite answers writes with Unavailable while the server is read-only
+func (g *grpcEvents) rejectWrite() error {
+	if g.s.readOnly {
+		return status.Error(codes.Unavailable, "The service is in read-only mode for maintenance, try again later")
+	}
+	return nil
+}
+
+// validate fills in omitted fields and checks a create or update payload
+func (g *grpcEvents) validate(req *models.CreateEventRequest) error {
+	g.s.applyDefaults(req)
+	if err := g.s.Echo.Validator.Validate(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// internalError logs an unexpected error and hides it from the caller
+func (g *grpcEvents) internalError(msg string, err error) error {
+	g.s.logger.Error(msg, "error", err)
+	return status.Error(codes.Internal, "Internal error")
+}
+
+// getStoredEvent loads an event, answering NotFound when there is none
+func (g *grpcEvents) getStoredEvent(ctx context.Context, id uuid.UUID) (*models.Event, error) {
+	event, err := g.store.GetEventByID(ctx, id)
+	if errors.Is(err, repository.ErrEventNotFound) {
+		return nil, status.Error(codes.NotFound, "Event not found")
+	}
+	if err != nil {
+		return nil, g.internalError("Error getting event by ID", err)
+	}
+	return event, nil
+}
+
+// createEvent implements EventService.CreateEvent
+func (g *grpcEvents) createEvent(ctx context.Context, in *dynamicpb.Message) (proto.Message, error) {
+	if err := g.rejectWrite(); err != nil {
+		return nil, err
+	}
+
+	var req models.CreateEventRequest
+	if err := decodeMessage(in, &req); err != nil {
+		return nil, err
+	}
+	if err := g.validate(&req); err != nil {
+		return nil, err
+	}
+
+	p := grpcPrincipal(ctx)
+	event := &models.Event{}
+	if p != nil {
+		event.CreatedBy = &p.ID
+	}
+	req.ApplyTo(event)
+	if req.ID != "" {
+		event.ID = uuid.MustParse(req.ID)
+	}
+
+	err := g.store.InsertEvent(ctx, event, g.s.policyCheck(event, p))
+	if errors.Is(err, ErrWindowLimitExceeded) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if errors.Is(err, ErrOwnerLimitExceeded) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, repository.ErrEventExists) {
+		return nil, status.Error(codes.AlreadyExists, "An event with this ID already exists")
+	}
+	if err != nil {
+		return nil, g.internalError("Error inserting event", err)
+	}
+
+	g.s.Counter.Add(1)
+	g.s.Hub.Publish(EventChange{Type: ChangeCreated, Event: event})
+
+	return eventMessage(event)
+}
+
+// getEvent implements EventService.GetEvent
+func (g *grpcEvents) getEvent(ctx context.Context, in *dynamicpb.Message) (proto.Message, error) {
+	id, err := parseEventID(in)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := g.getStoredEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return eventMessage(event)
+}
+
+// listEvents implements EventService.ListEvents, ordered by start time like GET /events
+func (g *grpcEvents) listEvents(ctx context.Context, in *dynamicpb.Message) (proto.Message, error) {
+	var req struct {
+		Limit  int      `json:"limit"`
+		Offset int      `json:"offset"`
+		Status string   `json:"status"`
+		Owner  string   `json:"owner"`
+		Tags   []string `json:"tags"`
+	}
+	if err := decodeMessage(in, &req); err != nil {
+		return nil, err
+	}
+
+	if req.Limit < 0 || req.Limit > MaxPageLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid limit, expected a number between 1 and %d", MaxPageLimit)
+	}
+	if req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "Invalid offset, expected a non-negative number")
+	}
+	if req.Status != "" && !models.IsValidStatus(req.Status) {
+		return nil, status.Error(codes.InvalidArgument, models.InvalidStatus.Error())
+	}
+
+	filter := models.EventFilter{
+		Owner:  req.Owner,
+		Status: req.Status,
+		Tags:   req.Tags,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	total, err := g.store.CountEvents(ctx, filter)
+	if err != nil {
+		return nil, g.internalError("Error counting events", err)
+	}
+	events, err := g.store.GetAllEvents(ctx, filter)
+	if err != nil {
+		return nil, g.internalError("Error getting events", err)
+	}
+
+	data, err := marshalEventList(events, total)
+	return encodeMessage("EventList", data, err)
+}
+
+// updateEvent implements EventService.UpdateEvent, replacing the event like PUT /events/:id
+func (g *grpcEvents) updateEvent(ctx context.Context, in *dynamicpb.Message) (proto.Message, error) {
+	if err := g.rejectWrite(); err != nil {
+		return nil, err
+	}
+
+	id, err := parseEventID(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var req models.CreateEventRequest
+	if err := decodeMessage(in.Get(in.Descriptor().Fields().ByName("event")).Message().Interface(), &req); err != nil {
+		return nil, err
+	}
+
+	event, err := g.getStoredEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !mayModify(grpcPrincipal(ctx), event) {
+		return nil, status.Error(codes.PermissionDenied, "You can only modify your own events")
+	}
+
+	if err := g.validate(&req); err != nil {
+		return nil, err
+	}
+	req.ApplyTo(event)
+
+	err = g.store.UpdateEvent(ctx, event, nil)
+	if errors.Is(err, repository.ErrEventNotFound) {
+		return nil, status.Error(codes.NotFound, "Event not found")
+	}
+	if err != nil {
+		return nil, g.internalError("Error updating event", err)
+	}
+
+	// Reload to return the stored representation including created_at
+	updated, err := g.getStoredEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g.s.Hub.Publish(EventChange{Type: ChangeUpdated, Event: updated})
+
+	return eventMessage(updated)
+}
+
+// deleteEvent implements EventService.DeleteEvent
+func (g *grpcEvents) deleteEvent(ctx context.Context, in *dynamicpb.Message) (proto.Message, error) {
+	if err := g.rejectWrite(); err != nil {
+		return nil, err
+	}
+
+	id, err := parseEventID(in)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := g.getStoredEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !mayModify(grpcPrincipal(ctx), event) {
+		return nil, status.Error(codes.PermissionDenied, "You can only modify your own events")
+	}
+
+	err = g.store.DeleteEvent(ctx, id, nil)
+	if errors.Is(err, repository.ErrEventNotFound) {
+		return nil, status.Error(codes.NotFound, "Event not found")
+	}
+	if err != nil {
+		return nil, g.internalError("Error deleting event", err)
+	}
+
+	g.s.Counter.Add(-1)
+	g.s.Hub.Publish(EventChange{Type: ChangeDeleted, Event: event})
+
+	return dynamicpb.NewMessage(schemaMessage("DeleteEventResponse")), nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"challenge/config"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newGRPCClient serves the gRPC API of s over an in-memory listener and connects to it
+func newGRPCClient(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	srv := s.newGRPCServer()
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// callRPC calls an EventService method with the request given as protojson, authenticated
+// with key unless empty, and decodes the response into out, if not nil
+func callRPC(t *testing.T, conn *grpc.ClientConn, key, method, request string, out interface{}) error {
+	t.Helper()
+
+	desc := eventsSchema.Services().ByName("EventService").Methods().ByName(protoreflect.Name(method))
+	in := dynamicpb.NewMessage(desc.Input())
+	if err := protojson.Unmarshal([]byte(request), in); err != nil {
+		t.Fatalf("%s: encoding %s: %v", method, request, err)
+	}
+
+	ctx := context.Background()
+	if key != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", key)
+	}
+	reply := dynamicpb.NewMessage(desc.Output())
+	if err := conn.Invoke(ctx, "/"+grpcServiceName+"/"+method, in, reply); err != nil {
+		return err
+	}
+
+	if out != nil {
+		data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(reply)
+		if err != nil {
+			t.Fatalf("%s: encoding the reply as JSON: %v", method, err)
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s: decoding %s: %v", method, data, err)
+		}
+	}
+	return nil
+}
+
+// grpcEvent is the protojson form of the Event message
+type grpcEvent struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description *string  `json:"description"`
+	StartTime   string   `json:"start_time"`
+	EndTime     string   `json:"end_time"`
+	CreatedBy   *string  `json:"created_by"`
+	Status      string   `json:"status"`
+	Priority    int      `json:"priority"`
+	Tags        []string `json:"tags"`
+}
+
+// wantCode fails the test unless err is a status with code
+func wantCode(t *testing.T, call string, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Errorf("%s: code %v (%v), want %v", call, got, err, code)
+	}
+}
+
+func TestGRPCEventService(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"alice-key:alice", "bob-key:bob"}
+	})
+	conn := newGRPCClient(t, s)
+
+	var created grpcEvent
+	err := callRPC(t, conn, "alice-key", "CreateEvent", `{
+		"title": "Planning",
+		"description": "",
+		"start_time": "2025-03-01T10:00:00Z",
+		"end_time": "2025-03-01T11:00:00Z",
+		"priority": 2,
+		"tags": ["team"]
+	}`, &created)
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	if created.ID == "" || created.Title != "Planning" || created.Status != "confirmed" || created.Priority != 2 {
+		t.Errorf("CreateEvent = %+v", created)
+	}
+	if created.Description == nil || *created.Description != "" {
+		t.Errorf("CreateEvent description = %v, want set and empty", created.Description)
+	}
+	if created.CreatedBy == nil || *created.CreatedBy != "alice" {
+		t.Errorf("CreateEvent created_by = %v, want alice", created.CreatedBy)
+	}
+	if created.StartTime != "2025-03-01T10:00:00Z" || created.EndTime != "2025-03-01T11:00:00Z" {
+		t.Errorf("CreateEvent times = %s to %s", created.StartTime, created.EndTime)
+	}
+
+	var got grpcEvent
+	if err := callRPC(t, conn, "bob-key", "GetEvent", `{"id": "`+created.ID+`"}`, &got); err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if got.ID != created.ID || got.Title != "Planning" || len(got.Tags) != 1 || got.Tags[0] != "team" {
+		t.Errorf("GetEvent = %+v, want the created event", got)
+	}
+
+	var list struct {
+		Events []grpcEvent `json:"events"`
+		Total  string      `json:"total"`
+	}
+	if err := callRPC(t, conn, "bob-key", "ListEvents", `{"tags": ["team"]}`, &list); err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	// protojson encodes int64 as a string
+	if list.Total != "1" || len(list.Events) != 1 || list.Events[0].ID != created.ID {
+		t.Errorf("ListEvents = %+v, want the created event", list)
+	}
+
+	update := `{"id": "` + created.ID + `", "event": {"title": "Planning v2", "start_time": "2025-03-01T10:00:00Z", "end_time": "2025-03-01T12:00:00Z"}}`
+	wantCode(t, "UpdateEvent by another owner", callRPC(t, conn, "bob-key", "UpdateEvent", update, nil), codes.PermissionDenied)
+
+	var updated grpcEvent
+	if err := callRPC(t, conn, "alice-key", "UpdateEvent", update, &updated); err != nil {
+		t.Fatalf("UpdateEvent: %v", err)
+	}
+	if updated.Title != "Planning v2" || updated.EndTime != "2025-03-01T12:00:00Z" {
+		t.Errorf("UpdateEvent = %+v", updated)
+	}
+
+	if err := callRPC(t, conn, "alice-key", "DeleteEvent", `{"id": "`+created.ID+`"}`, nil); err != nil {
+		t.Fatalf("DeleteEvent: %v", err)
+	}
+	wantCode(t, "GetEvent after delete", callRPC(t, conn, "alice-key", "GetEvent", `{"id": "`+created.ID+`"}`, nil), codes.NotFound)
+}
+
+func TestGRPCErrorCodes(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"alice-key:alice"}
+	})
+	conn := newGRPCClient(t, s)
+
+	tests := []struct {
+		name, key, method, request string
+		want                       codes.Code
+	}{
+		{"missing key", "", "GetEvent", `{"id": "7c9e6679-7425-40de-944b-e07fc1f99a43"}`, codes.Unauthenticated},
+		{"unknown key", "mallory-key", "ListEvents", `{}`, codes.Unauthenticated},
+		{"unknown event", "alice-key", "GetEvent", `{"id": "7c9e6679-7425-40de-944b-e07fc1f99a43"}`, codes.NotFound},
+		{"deleting an unknown event", "alice-key", "DeleteEvent", `{"id": "7c9e6679-7425-40de-944b-e07fc1f99a43"}`, codes.NotFound},
+		{"invalid id", "alice-key", "GetEvent", `{"id": "not-a-uuid"}`, codes.InvalidArgument},
+		{"negative limit", "alice-key", "ListEvents", `{"limit": -1}`, codes.InvalidArgument},
+		{"unknown status", "alice-key", "ListEvents", `{"status": "postponed"}`, codes.InvalidArgument},
+		{"missing title", "alice-key", "CreateEvent", `{"start_time": "2025-03-01T10:00:00Z", "end_time": "2025-03-01T11:00:00Z"}`, codes.InvalidArgument},
+		{"end before start", "alice-key", "CreateEvent", `{"title": "Planning", "start_time": "2025-03-01T10:00:00Z", "end_time": "2025-03-01T09:00:00Z"}`, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.method, callRPC(t, conn, tt.key, tt.method, tt.request, nil), tt.want)
+		})
+	}
+}
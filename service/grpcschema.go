@@ -0,0 +1,176 @@
+package service
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// Register the well-known types the schema imports
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServiceName is the full name of the gRPC service
+const grpcServiceName = "events.v1.EventService"
+
+// protoField describes a field of a schema message
+// kind is a scalar type, or TYPE_MESSAGE with the full name of the message in typeName
+type protoField struct {
+	name     string
+	number   int32
+	kind     descriptorpb.FieldDescriptorProto_Type
+	typeName string
+	repeated bool
+	// optional gives a proto3 scalar field explicit presence
+	optional bool
+}
+
+// protoMessage builds the descriptor of a message; field JSON names are the proto names so
+// protojson output matches the REST payloads
+func protoMessage(name string, fields ...protoField) *descriptorpb.DescriptorProto {
+	message := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	for _, f := range fields {
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(f.name),
+			JsonName: proto.String(f.name),
+			Number:   proto.Int32(f.number),
+			Type:     f.kind.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if f.typeName != "" {
+			field.TypeName = proto.String("." + f.typeName)
+		}
+		if f.repeated {
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		}
+		if f.optional {
+			// proto3 optional fields live in a synthetic oneof of their own
+			field.Proto3Optional = proto.Bool(true)
+			field.OneofIndex = proto.Int32(int32(len(message.OneofDecl)))
+			message.OneofDecl = append(message.OneofDecl, &descriptorpb.OneofDescriptorProto{
+				Name: proto.String("_" + f.name),
+			})
+		}
+		message.Field = append(message.Field, field)
+	}
+	return message
+}
+
+// protoMethod builds the descriptor of a unary RPC
+func protoMethod(name, input, output string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(".events.v1." + input),
+		OutputType: proto.String(".events.v1." + output),
+	}
+}
+
+const (
+	protoString    = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	protoInt32     = descriptorpb.FieldDescriptorProto_TYPE_INT32
+	protoInt64     = descriptorpb.FieldDescriptorProto_TYPE_INT64
+	protoBytes     = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+	protoMessageTy = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	timestampType  = "google.protobuf.Timestamp"
+)
+
+// eventsSchema is the descriptor of proto/events.proto, built by hand since the messages are
+// encoded without generated code. It is registered globally so server reflection serves it.
+var eventsSchema = func() protoreflect.FileDescriptor {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("events.proto"),
+		Package:    proto.String("events.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto", "google/protobuf/struct.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			protoMessage("Event",
+				protoField{name: "id", number: 1, kind: protoString},
+				protoField{name: "title", number: 2, kind: protoString},
+				protoField{name: "description", number: 3, kind: protoString, optional: true},
+				protoField{name: "start_time", number: 4, kind: protoMessageTy, typeName: timestampType},
+				protoField{name: "end_time", number: 5, kind: protoMessageTy, typeName: timestampType},
+				protoField{name: "created_at", number: 6, kind: protoMessageTy, typeName: timestampType},
+				protoField{name: "created_by", number: 7, kind: protoString, optional: true},
+				protoField{name: "updated_at", number: 8, kind: protoMessageTy, typeName: timestampType},
+				protoField{name: "status", number: 9, kind: protoString},
+				protoField{name: "deleted_at", number: 10, kind: protoMessageTy, typeName: timestampType},
+				protoField{name: "metadata_json", number: 11, kind: protoBytes},
+				protoField{name: "recurrence", number: 12, kind: protoMessageTy, typeName: "events.v1.Recurrence"},
+				protoField{name: "links", number: 13, kind: protoString, repeated: true},
+				protoField{name: "meeting_url", number: 14, kind: protoString, optional: true},
+				protoField{name: "priority", number: 15, kind: protoInt32},
+				protoField{name: "tags", number: 16, kind: protoString, repeated: true},
+				protoField{name: "timezone", number: 17, kind: protoString},
+			),
+			protoMessage("Recurrence",
+				protoField{name: "frequency", number: 1, kind: protoString},
+				protoField{name: "timezone", number: 2, kind: protoString},
+				protoField{name: "count", number: 3, kind: protoInt32},
+				protoField{name: "until", number: 4, kind: protoMessageTy, typeName: timestampType},
+				protoField{name: "excluded_dates", number: 5, kind: protoMessageTy, typeName: timestampType, repeated: true},
+			),
+			protoMessage("EventList",
+				protoField{name: "events", number: 1, kind: protoMessageTy, typeName: "events.v1.Event", repeated: true},
+				protoField{name: "total", number: 2, kind: protoInt64},
+			),
+			protoMessage("CreateEventRequest",
+				protoField{name: "id", number: 1, kind: protoString},
+				protoField{name: "title", number: 2, kind: protoString},
+				protoField{name: "description", number: 3, kind: protoString, optional: true},
+				protoField{name: "start_time", number: 4, kind: protoString},
+				protoField{name: "end_time", number: 5, kind: protoString},
+				protoField{name: "status", number: 6, kind: protoString},
+				protoField{name: "metadata", number: 7, kind: protoMessageTy, typeName: "google.protobuf.Struct"},
+				protoField{name: "recurrence", number: 8, kind: protoMessageTy, typeName: "events.v1.Recurrence"},
+				protoField{name: "links", number: 9, kind: protoString, repeated: true},
+				protoField{name: "meeting_url", number: 10, kind: protoString, optional: true},
+				protoField{name: "priority", number: 11, kind: protoInt32, optional: true},
+				protoField{name: "tags", number: 12, kind: protoString, repeated: true},
+			),
+			protoMessage("GetEventRequest",
+				protoField{name: "id", number: 1, kind: protoString},
+			),
+			protoMessage("ListEventsRequest",
+				protoField{name: "limit", number: 1, kind: protoInt32},
+				protoField{name: "offset", number: 2, kind: protoInt32},
+				protoField{name: "status", number: 3, kind: protoString},
+				protoField{name: "owner", number: 4, kind: protoString},
+				protoField{name: "tags", number: 5, kind: protoString, repeated: true},
+			),
+			protoMessage("UpdateEventRequest",
+				protoField{name: "id", number: 1, kind: protoString},
+				protoField{name: "event", number: 2, kind: protoMessageTy, typeName: "events.v1.CreateEventRequest"},
+			),
+			protoMessage("DeleteEventRequest",
+				protoField{name: "id", number: 1, kind: protoString},
+			),
+			protoMessage("DeleteEventResponse"),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("EventService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				protoMethod("CreateEvent", "CreateEventRequest", "Event"),
+				protoMethod("GetEvent", "GetEventRequest", "Event"),
+				protoMethod("ListEvents", "ListEventsRequest", "EventList"),
+				protoMethod("UpdateEvent", "UpdateEventRequest", "Event"),
+				protoMethod("DeleteEvent", "DeleteEventRequest", "DeleteEventResponse"),
+			},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		panic("invalid events schema: " + err.Error())
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic("failed to register events schema: " + err.Error())
+	}
+	return fd
+}()
+
+// schemaMessage returns the descriptor of the named message of the schema
+func schemaMessage(name protoreflect.Name) protoreflect.MessageDescriptor {
+	return eventsSchema.Messages().ByName(name)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"challenge/models"
+	"sync"
+)
+
+// Change types published to stream subscribers
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// subscriberBuffer is the number of pending changes a subscriber may queue
+// before new changes are dropped for it
+const subscriberBuffer = 16
+
+// EventChange describes a mutation of an event
+type EventChange struct {
+	Type  string        `json:"type"`
+	Event *models.Event `json:"event"`
+}
+
+// Hub is an in-process pub/sub hub fanning out event changes to subscribers
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[chan EventChange]struct{}
+}
+
+// NewHub creates a new hub with no subscribers
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan EventChange]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber and returns its channel
+func (h *Hub) Subscribe() chan EventChange {
+	ch := make(chan EventChange, subscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (h *Hub) Unsubscribe(ch chan EventChange) {
+	h.mu.Lock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+	h.mu.Unlock()
+}
+
+// Publish sends a change to every subscriber
+// Slow subscribers whose buffer is full miss the change instead of blocking the publisher
+func (h *Hub) Publish(change EventChange) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
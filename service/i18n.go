@@ -0,0 +1,113 @@
+package service
+
+import (
+	"challenge/models"
+	"fmt"
+	"strconv"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// defaultLanguage is used when the client doesn't ask for a supported language
+// Its messages are the ones defined next to each error
+const defaultLanguage = "en"
+
+// codeWindowLimitExceeded is the error code of ErrWindowLimitExceeded
+const codeWindowLimitExceeded = "WINDOW_LIMIT_EXCEEDED"
+
+// codeOwnerLimitExceeded is the error code of ErrOwnerLimitExceeded
+const codeOwnerLimitExceeded = "OWNER_LIMIT_EXCEEDED"
+
+// catalog holds the translated error messages by language and error code
+// Messages of models.CodeInvalidField take the field name as argument
+var catalog = map[string]map[string]string{
+	"es": {
+		models.TitleTooLong.Code:       "el título supera la longitud máxima de 100 caracteres",
+		models.TitleEmpty.Code:         "el título no debe estar vacío",
+		models.EndTimeBeforeStart.Code: "end_time debe ser posterior a start_time",
+		models.InvalidTimeFormat.Code:  "formato de fecha no válido, se esperaba el formato ISO 8601",
+		models.MissingTimeZone.Code:    "las fechas deben seguir RFC 3339 con un desfase horario como Z o +02:00",
+		models.StartTimeRequired.Code:  "start_time es obligatorio",
+		models.EndTimeRequired.Code:    "end_time es obligatorio",
+		models.IDRequired.Code:         "id es obligatorio",
+		models.InvalidID.Code:          "id debe ser un UUID",
+		models.CreatedAtRequired.Code:  "created_at es obligatorio",
+		models.InvalidStatus.Code:      "status debe ser confirmed, tentative o cancelled",
+		models.MetadataNotObject.Code:  "metadata debe ser un objeto JSON",
+		models.MetadataTooLarge.Code:   "metadata supera el tamaño máximo de 4096 bytes",
+		models.ZeroDuration.Code:       "end_time no debe ser igual a start_time",
+		models.TooManyLinks.Code:       "links supera el máximo de 10 enlaces",
+		models.InvalidLink.Code:        "links debe contener URLs http o https absolutas de como máximo 2048 caracteres",
+		models.InvalidMeetingURL.Code:  "meeting_url debe ser una URL https absoluta de como máximo 2048 caracteres",
+		models.InvalidPriority.Code:    "priority debe estar entre 0 y 9",
+		models.InvalidTitle.Code:       "el título no debe contener caracteres de control",
+		models.InvalidDescription.Code: "la descripción no debe contener caracteres de control salvo saltos de línea y tabulaciones",
+		models.YearOutOfRange.Code:     "las fechas deben estar dentro del rango de años admitido",
+		models.TooManyTags.Code:        "tags supera el máximo de 20 etiquetas",
+		models.InvalidTag.Code:         "tags debe contener etiquetas distintas, no vacías, de como máximo 50 caracteres y sin espacios al inicio o al final",
+		models.CodeInvalidField:        "%s no es válido",
+		codeWindowLimitExceeded:        "hay demasiados eventos programados alrededor de la hora solicitada",
+		codeOwnerLimitExceeded:         "se ha alcanzado el número máximo de eventos activos para este usuario",
+
+		models.InvalidRecurrenceFrequency.Code: "la frecuencia de recurrence debe ser daily",
+		models.InvalidRecurrenceTimeZone.Code:  "el timezone de recurrence debe ser una zona horaria IANA",
+		models.InvalidRecurrenceCount.Code:     "el count de recurrence no debe ser negativo",
+		models.RecurrenceUntilBeforeStart.Code: "el until de recurrence debe ser posterior a start_time",
+		models.InvalidRecurrenceExclusion.Code: "los excluded_dates de recurrence deben ser como máximo 100 horas de inicio de ocurrencias",
+
+		models.InvalidTargetDate.Code:    "target_date debe ser una fecha como 2025-03-01",
+		models.InvalidCloneTimeZone.Code: "timezone debe ser una zona horaria IANA",
+	},
+}
+
+// requestLanguage picks the supported language the client prefers in Accept-Language
+func requestLanguage(c echo.Context) string {
+	best, bestQuality := defaultLanguage, 0.0
+
+	for _, part := range strings.Split(c.Request().Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+
+		// Only the primary subtag matters, es-AR is served as es
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, ok := catalog[lang]; !ok && lang != defaultLanguage {
+			continue
+		}
+		if quality > bestQuality {
+			best, bestQuality = lang, quality
+		}
+	}
+	return best
+}
+
+// localize returns the message of an error code in lang, falling back to the default message
+func localize(lang, code, message string) string {
+	if translated, ok := catalog[lang][code]; ok {
+		return translated
+	}
+	return message
+}
+
+// localizeFieldErrors returns a copy of errs with every message in lang
+func localizeFieldErrors(lang string, errs models.FieldErrors) models.FieldErrors {
+	localized := make(models.FieldErrors, len(errs))
+	for i, fe := range errs {
+		if fe.Code == models.CodeInvalidField {
+			if format, ok := catalog[lang][fe.Code]; ok {
+				fe.Message = fmt.Sprintf(format, fe.Field)
+			}
+		} else {
+			fe.Message = localize(lang, fe.Code, fe.Message)
+		}
+		localized[i] = fe
+	}
+	return localized
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bufio"
+	"challenge/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// icsTimeFormat is the iCalendar UTC date-time format
+const icsTimeFormat = "20060102T150405Z"
+
+// icsLocalTimeFormat is the iCalendar local date-time format, qualified by a TZID parameter
+const icsLocalTimeFormat = "20060102T150405"
+
+// icsMaxLineLength is the maximum length of a content line before it must be folded
+const icsMaxLineLength = 75
+
+// icsEscaper escapes TEXT property values as required by RFC 5545
+var icsEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// icsWriter writes iCalendar content lines, folding long lines
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a single content line terminated by CRLF
+func (iw *icsWriter) line(content string) {
+	if iw.err != nil {
+		return
+	}
+
+	// Fold lines longer than the limit, continuing with a leading space
+	// that counts towards the limit of the continuation line
+	limit := icsMaxLineLength
+	for len(content) > limit {
+		cut := limit
+		// Don't split a UTF-8 sequence
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		_, iw.err = iw.w.WriteString(content[:cut] + "\r\n ")
+		if iw.err != nil {
+			return
+		}
+		content = content[cut:]
+		limit = icsMaxLineLength - 1
+	}
+	_, iw.err = iw.w.WriteString(content + "\r\n")
+}
+
+// text writes a property with an escaped TEXT value
+func (iw *icsWriter) text(name, value string) {
+	iw.line(name + ":" + icsEscaper.Replace(value))
+}
+
+// time writes a property with a UTC date-time value
+func (iw *icsWriter) time(name string, t time.Time) {
+	iw.line(name + ":" + t.UTC().Format(icsTimeFormat))
+}
+
+// localTime writes a property with a date-time value in the given IANA time zone
+func (iw *icsWriter) localTime(name string, t time.Time, loc *time.Location) {
+	iw.line(name + ";TZID=" + loc.String() + ":" + t.In(loc).Format(icsLocalTimeFormat))
+}
+
+// recurrence writes the start, end and RRULE of a recurring event
+// Start and end are written in the recurrence time zone so clients keep the local
+// wall-clock times across DST transitions
+func (iw *icsWriter) recurrence(event *models.Event) {
+	loc, err := time.LoadLocation(event.Recurrence.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	iw.localTime("DTSTART", event.StartTime, loc)
+	iw.localTime("DTEND", event.EndTime, loc)
+
+	rule := "RRULE:FREQ=" + strings.ToUpper(event.Recurrence.Frequency)
+	if event.Recurrence.Count > 0 {
+		rule += ";COUNT=" + strconv.Itoa(event.Recurrence.Count)
+	}
+	if event.Recurrence.Until != nil {
+		rule += ";UNTIL=" + event.Recurrence.Until.UTC().Format(icsTimeFormat)
+	}
+	iw.line(rule)
+
+	if len(event.Recurrence.ExcludedDates) > 0 {
+		dates := make([]string, len(event.Recurrence.ExcludedDates))
+		for i, excluded := range event.Recurrence.ExcludedDates {
+			dates[i] = excluded.In(loc).Format(icsLocalTimeFormat)
+		}
+		iw.line("EXDATE;TZID=" + loc.String() + ":" + strings.Join(dates, ","))
+	}
+}
+
+// event writes an event as a VEVENT component
+func (iw *icsWriter) event(event *models.Event) {
+	iw.line("BEGIN:VEVENT")
+	iw.line("UID:" + event.ID.String())
+	iw.time("DTSTAMP", event.UpdatedAt)
+	iw.time("CREATED", event.CreatedAt)
+	iw.time("LAST-MODIFIED", event.UpdatedAt)
+	if event.Recurrence != nil {
+		iw.recurrence(event)
+	} else {
+		iw.time("DTSTART", event.StartTime)
+		iw.time("DTEND", event.EndTime)
+	}
+	iw.text("SUMMARY", event.Title)
+	if event.Description != nil && *event.Description != "" {
+		iw.text("DESCRIPTION", *event.Description)
+	}
+	if event.Status != "" {
+		iw.line("STATUS:" + strings.ToUpper(event.Status))
+	}
+	if event.Priority > 0 {
+		iw.line("PRIORITY:" + strconv.Itoa(event.Priority))
+	}
+	if event.MeetingURL != nil {
+		// URL is standard; X-GOOGLE-CONFERENCE makes Google Calendar show a join button
+		iw.line("URL:" + *event.MeetingURL)
+		iw.line("X-GOOGLE-CONFERENCE:" + *event.MeetingURL)
+	}
+	if len(event.Tags) > 0 {
+		categories := make([]string, len(event.Tags))
+		for i, tag := range event.Tags {
+			categories[i] = icsEscaper.Replace(tag)
+		}
+		iw.line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+	for _, link := range event.Links {
+		// URI values aren't TEXT, so they aren't escaped
+		iw.line("ATTACH:" + link)
+	}
+	iw.line("END:VEVENT")
+}
+
+// exportCalendar handles GET /events/export.ics
+// Streams every event as an iCalendar (RFC 5545) file
+func (s *Server) exportCalendar(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/calendar; charset=utf-8")
+	w.Header().Set(echo.HeaderContentDisposition, `attachment; filename="events.ics"`)
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	iw := &icsWriter{w: bw}
+
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//tlk_events//Events API//EN")
+	iw.line("CALSCALE:GREGORIAN")
+
+	err := s.DB.StreamEvents(ctx, func(event *models.Event) error {
+		iw.event(event)
+		return iw.err
+	})
+	if err != nil {
+		// Headers are already sent, so the truncated body is all the client gets
+		s.logger.Error("Error exporting calendar", "error", err)
+		return nil
+	}
+
+	iw.line("END:VCALENDAR")
+	if iw.err == nil {
+		iw.err = bw.Flush()
+	}
+	if iw.err != nil {
+		s.logger.Error("Error writing calendar", "error", iw.err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"challenge/config"
+	"challenge/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// doIdempotent is doWithKey sending idempotencyKey in the Idempotency-Key header
+func doIdempotent(t *testing.T, s *Server, key, idempotencyKey, method, path, body string, out interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(HeaderAPIKey, key)
+	req.Header.Set(HeaderIdempotencyKey, idempotencyKey)
+	rec := httptest.NewRecorder()
+	s.Echo.ServeHTTP(rec, req)
+
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func TestIdempotencyKeyReplaysOnlyTheSameRequest(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"alice-key:alice", "bob-key:bob"}
+	})
+
+	const planning = `{"title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`
+
+	var created models.Event
+	rec := doIdempotent(t, s, "alice-key", "key-1", http.MethodPost, "/api/v1/events", planning, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var replayed models.Event
+	rec = doIdempotent(t, s, "alice-key", "key-1", http.MethodPost, "/api/v1/events", planning, &replayed)
+	if rec.Code != http.StatusOK || replayed.ID != created.ID {
+		t.Errorf("retry: status %d, id %s, want 200 with %s", rec.Code, replayed.ID, created.ID)
+	}
+
+	rec = doIdempotent(t, s, "alice-key", "key-1", http.MethodPost, "/api/v1/events",
+		`{"title":"Review","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different body: status %d, want 422: %s", rec.Code, rec.Body)
+	}
+
+	// Another caller choosing the same key gets an event of their own
+	var other models.Event
+	rec = doIdempotent(t, s, "bob-key", "key-1", http.MethodPost, "/api/v1/events", planning, &other)
+	if rec.Code != http.StatusCreated || other.ID == created.ID {
+		t.Errorf("another caller: status %d, id %s, want 201 with a new event", rec.Code, other.ID)
+	}
+	if other.CreatedBy == nil || *other.CreatedBy != "bob" {
+		t.Errorf("another caller: created_by = %v, want bob", other.CreatedBy)
+	}
+
+	// A key used for an update isn't replayed as a creation
+	path := "/api/v1/events/" + created.ID.String()
+	rec = doIdempotent(t, s, "alice-key", "key-2", http.MethodPut, path,
+		`{"title":"Planning v2","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d: %s", rec.Code, rec.Body)
+	}
+	var fresh models.Event
+	rec = doIdempotent(t, s, "alice-key", "key-2", http.MethodPost, "/api/v1/events", planning, &fresh)
+	if rec.Code != http.StatusCreated || fresh.ID == created.ID {
+		t.Errorf("create with an update's key: status %d, id %s, want 201 with a new event", rec.Code, fresh.ID)
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// conflictModes are the accepted values of the on_conflict query parameter
+var conflictModes = map[string]bool{
+	models.OnConflictFail:  true,
+	models.OnConflictSkip:  true,
+	models.OnConflictAllow: true,
+}
+
+// importModes are the accepted values of the mode query parameter
+var importModes = map[string]bool{
+	models.ImportModeAtomic:  true,
+	models.ImportModePartial: true,
+}
+
+// importEvents handles POST /events/import
+// Creates the events of a JSON array of create requests in a single transaction, checking
+// each one for overlaps with the stored events and the other imported events. on_conflict
+// chooses what happens to conflicting events: fail (default) imports nothing and answers 409,
+// skip leaves them out and allow imports them anyway. Every event's conflicts are reported.
+// Events keep the id they are sent with; those whose id is taken are left out as existing,
+// so importing an export twice doesn't duplicate it. An invalid event fails the whole import
+// with 400, unless mode=partial, where it is reported as invalid and the response is
+// 207 Multi-Status.
+func (s *Server) importEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	importMode := c.QueryParam("mode")
+	if importMode == "" {
+		importMode = models.ImportModeAtomic
+	}
+	if !importModes[importMode] {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid mode, expected atomic or partial",
+		})
+	}
+	partial := importMode == models.ImportModePartial
+
+	mode := c.QueryParam("on_conflict")
+	if mode == "" {
+		mode = models.OnConflictFail
+	}
+	if !conflictModes[mode] {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid on_conflict, expected fail, skip or allow",
+		})
+	}
+
+	var reqs []*models.CreateEventRequest
+	if err := c.Bind(&reqs); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	if len(reqs) > models.MaxImportSize {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("at most %d events can be imported at once", models.MaxImportSize),
+		})
+	}
+
+	// Invalid events of a partial import stay nil in events and are reported in invalid
+	events := make([]*models.Event, len(reqs))
+	invalid := make(map[int]models.FieldErrors)
+	lang := requestLanguage(c)
+	for i, req := range reqs {
+		if req == nil {
+			if partial {
+				invalid[i] = models.FieldErrors{{Code: models.CodeInvalidField, Message: "invalid event"}}
+				continue
+			}
+			return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("event %d: invalid event", i),
+			})
+		}
+
+		s.applyDefaults(req)
+		if err := c.Validate(req); err != nil {
+			if partial {
+				invalid[i] = localizeFieldErrors(lang, toFieldErrors(err))
+				continue
+			}
+			return itemValidationError(c, i, err)
+		}
+
+		// IDs are chosen up front so the results can name the events before they're stored
+		event := &models.Event{
+			ID:        s.DB.NewID(),
+			CreatedBy: principalID(c),
+		}
+		if req.ID != "" {
+			// Validation checked that it is a UUID
+			event.ID, _ = uuid.Parse(req.ID)
+		}
+		req.ApplyTo(event)
+		events[i] = event
+	}
+
+	results, err := s.importConflicts(ctx, events, mode)
+	if err != nil {
+		s.logger.Error("Error checking imported events", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to import events",
+		})
+	}
+
+	for i, errs := range invalid {
+		results[i].Status = models.ImportStatusInvalid
+		results[i].Error = errs[0].Message
+		results[i].Code = errs[0].Code
+		if errs[0].Field != "" {
+			results[i].Fields = errs
+		}
+	}
+	if len(invalid) > 0 {
+		c.Response().Header().Set("Content-Language", lang)
+	}
+
+	var imported []*models.Event
+	failed := false
+	for i, result := range results {
+		switch result.Status {
+		case models.ImportStatusImported:
+			imported = append(imported, events[i])
+		case models.ImportStatusConflict:
+			failed = true
+		}
+	}
+
+	if failed {
+		for _, result := range results {
+			if result.Status == models.ImportStatusImported {
+				result.Status = models.ImportStatusAborted
+			}
+		}
+		return c.JSON(http.StatusConflict, models.ImportResponse{
+			Imported: 0,
+			Results:  results,
+		})
+	}
+
+	if len(imported) > 0 {
+		if err := s.DB.InsertEvents(ctx, imported); err != nil {
+			if errors.Is(err, repository.ErrEventExists) {
+				return echo.NewHTTPError(http.StatusConflict, map[string]string{
+					"error": "An imported event's id was taken while importing, retry the import",
+				})
+			}
+			s.logger.Error("Error importing events", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to import events",
+			})
+		}
+	}
+
+	s.Counter.Add(int64(len(imported)))
+	for i, result := range results {
+		if result.Status == models.ImportStatusImported {
+			result.ID = &events[i].ID
+			s.Hub.Publish(EventChange{Type: ChangeCreated, Event: events[i]})
+		}
+	}
+
+	status := http.StatusOK
+	if partial {
+		status = http.StatusMultiStatus
+	}
+	return c.JSON(status, models.ImportResponse{
+		Imported: len(imported),
+		Results:  results,
+	})
+}
+
+// importConflicts finds the overlaps of every imported event and decides its status
+// Events whose id is taken by a stored event, or by an earlier imported one, exist already:
+// they are left out whatever on_conflict says, naming the event holding the id. The others
+// are compared with the stored events and with the earlier imported events that are kept,
+// so with on_conflict=skip the first of two overlapping events wins. Like the availability
+// check, cancelled events don't conflict. Invalid events, left nil, are neither checked nor
+// compared with. The stored events overlapping the whole batch are loaded once and matched
+// in memory, like the imported ones.
+func (s *Server) importConflicts(ctx context.Context, events []*models.Event, mode string) ([]*models.ImportResult, error) {
+	ids := make([]uuid.UUID, 0, len(events))
+	var from, to time.Time
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		ids = append(ids, event.ID)
+		if event.Status == models.StatusCancelled {
+			continue
+		}
+		if from.IsZero() || event.StartTime.Before(from) {
+			from = event.StartTime
+		}
+		if event.EndTime.After(to) {
+			to = event.EndTime
+		}
+	}
+	existing, err := s.DB.ExistingEventIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find existing events: %w", err)
+	}
+	var stored []*models.Event
+	if !from.IsZero() {
+		stored, err = s.DB.FindOverlappingEvents(ctx, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find overlapping events: %w", err)
+		}
+	}
+	// Index of the first imported event with each id
+	first := make(map[uuid.UUID]int)
+
+	results := make([]*models.ImportResult, len(events))
+	for i, event := range events {
+		result := &models.ImportResult{
+			Index:              i,
+			Status:             models.ImportStatusImported,
+			ConflictingIDs:     []uuid.UUID{},
+			ConflictingIndexes: []int{},
+		}
+		results[i] = result
+
+		if event == nil {
+			continue
+		}
+
+		if existing[event.ID] {
+			result.Status = models.ImportStatusExists
+			result.ConflictingIDs = append(result.ConflictingIDs, event.ID)
+			continue
+		}
+		if j, ok := first[event.ID]; ok {
+			result.Status = models.ImportStatusExists
+			result.ConflictingIndexes = append(result.ConflictingIndexes, j)
+			continue
+		}
+		first[event.ID] = i
+
+		if event.Status == models.StatusCancelled {
+			continue
+		}
+
+		for _, other := range stored {
+			if other.StartTime.Before(event.EndTime) && other.EndTime.After(event.StartTime) {
+				result.ConflictingIDs = append(result.ConflictingIDs, other.ID)
+			}
+		}
+
+		var overlapping []int
+		for j := 0; j < i; j++ {
+			other := events[j]
+			if other == nil || results[j].Status == models.ImportStatusSkipped || results[j].Status == models.ImportStatusExists ||
+				other.Status == models.StatusCancelled {
+				continue
+			}
+			if other.StartTime.Before(event.EndTime) && other.EndTime.After(event.StartTime) {
+				overlapping = append(overlapping, j)
+			}
+		}
+		result.ConflictingIndexes = append(result.ConflictingIndexes, overlapping...)
+
+		if len(result.ConflictingIDs) == 0 && len(overlapping) == 0 {
+			continue
+		}
+
+		switch mode {
+		case models.OnConflictSkip:
+			result.Status = models.ImportStatusSkipped
+			continue
+		case models.OnConflictFail:
+			result.Status = models.ImportStatusConflict
+		}
+
+		// The earlier events are kept, so they conflict with this one as well
+		for _, j := range overlapping {
+			results[j].ConflictingIndexes = append(results[j].ConflictingIndexes, i)
+			if mode == models.OnConflictFail {
+				results[j].Status = models.ImportStatusConflict
+			}
+		}
+	}
+	return results, nil
+}
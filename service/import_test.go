@@ -0,0 +1,103 @@
+package service
+
+import (
+	"challenge/config"
+	"challenge/models"
+	"challenge/repository"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// importEvents posts body to the import endpoint with an admin key and decodes the response
+func importEvents(t *testing.T, s *Server, body string) (int, models.ImportResponse) {
+	t.Helper()
+
+	var resp models.ImportResponse
+	rec := doWithKey(t, s, "admin-key", http.MethodPost, "/api/v1/events/import", body, &resp)
+	return rec.Code, resp
+}
+
+func TestImportKeepsIDsAndIsIdempotent(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"admin-key:alice:admin"}
+	})
+
+	const id = "7c9e6679-7425-40de-944b-e07fc1f99a43"
+	body := `[
+		{"id":"` + id + `","title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"},
+		{"id":"` + id + `","title":"Planning copy","start_time":"2025-03-02T10:00:00Z","end_time":"2025-03-02T11:00:00Z"}
+	]`
+
+	code, resp := importEvents(t, s, body)
+	if code != http.StatusOK {
+		t.Fatalf("first import: status %d", code)
+	}
+	if resp.Imported != 1 {
+		t.Fatalf("first import: imported %d, want 1", resp.Imported)
+	}
+	if got := resp.Results[0]; got.Status != models.ImportStatusImported || got.ID == nil || got.ID.String() != id {
+		t.Errorf("first import: result 0 = %+v, want imported with id %s", got, id)
+	}
+	if got := resp.Results[1]; got.Status != models.ImportStatusExists || len(got.ConflictingIndexes) != 1 || got.ConflictingIndexes[0] != 0 {
+		t.Errorf("first import: result 1 = %+v, want exists naming index 0", got)
+	}
+
+	code, resp = importEvents(t, s, body)
+	if code != http.StatusOK {
+		t.Fatalf("second import: status %d", code)
+	}
+	if resp.Imported != 0 {
+		t.Errorf("second import: imported %d, want 0", resp.Imported)
+	}
+	if got := resp.Results[0]; got.Status != models.ImportStatusExists || len(got.ConflictingIDs) != 1 || got.ConflictingIDs[0].String() != id {
+		t.Errorf("second import: result 0 = %+v, want exists naming %s", got, id)
+	}
+
+	count, err := s.DB.CountEvents(context.Background(), models.EventFilter{})
+	if err != nil {
+		t.Fatalf("CountEvents: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("stored %d events, want 1", count)
+	}
+}
+
+func TestImportConflictsQueriesOncePerBatch(t *testing.T) {
+	s := newTestServer(t, nil)
+	ctx := context.Background()
+
+	day := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	stored := &models.Event{Title: "Stored", StartTime: day.AddDate(0, 0, 5), EndTime: day.AddDate(0, 0, 5).Add(time.Hour)}
+	if err := s.DB.InsertEvent(ctx, stored, nil); err != nil {
+		t.Fatalf("InsertEvent: %v", err)
+	}
+
+	// One event a day, the sixth overlapping the stored one
+	events := make([]*models.Event, 20)
+	for i := range events {
+		start := day.AddDate(0, 0, i).Add(30 * time.Minute)
+		events[i] = &models.Event{ID: uuid.New(), Title: "Imported", StartTime: start, EndTime: start.Add(time.Hour)}
+	}
+
+	counted, counter := repository.WithQueryCounter(ctx)
+	results, err := s.importConflicts(counted, events, models.OnConflictSkip)
+	if err != nil {
+		t.Fatalf("importConflicts: %v", err)
+	}
+	if got := counter.Count(); got != 2 {
+		t.Errorf("ran %d queries, want 2 whatever the size of the batch", got)
+	}
+	for i, result := range results {
+		if i == 5 {
+			if result.Status != models.ImportStatusSkipped || len(result.ConflictingIDs) != 1 || result.ConflictingIDs[0] != stored.ID {
+				t.Errorf("result 5 = %+v, want skipped naming %s", result, stored.ID)
+			}
+		} else if result.Status != models.ImportStatusImported || len(result.ConflictingIDs) != 0 {
+			t.Errorf("result %d = %+v, want imported", i, result)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"challenge/models"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// MIMEJSONAPI is the JSON:API (jsonapi.org) media type
+const MIMEJSONAPI = "application/vnd.api+json"
+
+// jsonAPIEventType is the JSON:API resource type of events
+const jsonAPIEventType = "events"
+
+// jsonAPIResource is an event as a JSON:API resource object: its members other than the
+// id become attributes
+type jsonAPIResource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// LID is the local ID a client gave the resource before it had one, see respondClientID
+	LID        string                     `json:"lid,omitempty"`
+	Attributes map[string]json.RawMessage `json:"attributes"`
+}
+
+// jsonAPIDocument is the top-level JSON:API document wrapping a resource or a list of them
+type jsonAPIDocument struct {
+	Data  interface{}       `json:"data"`
+	Links map[string]string `json:"links,omitempty"`
+	Meta  map[string]int    `json:"meta,omitempty"`
+}
+
+// wantsJSONAPI reports whether the request's Accept header asks for JSON:API
+// Plain JSON stays the default, including for Accept: */*
+func wantsJSONAPI(c echo.Context) bool {
+	for _, header := range c.Request().Header.Values(echo.HeaderAccept) {
+		for _, accepted := range strings.Split(header, ",") {
+			mediaType, _, err := mime.ParseMediaType(accepted)
+			if err == nil && mediaType == MIMEJSONAPI {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toJSONAPIResource converts an event into a resource, keeping only the given attributes
+// when fields isn't empty
+func toJSONAPIResource(event *models.Event, fields []string) (jsonAPIResource, error) {
+	members, err := eventMembers(event)
+	if err != nil {
+		return jsonAPIResource{}, err
+	}
+	delete(members, "id")
+
+	if len(fields) > 0 {
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := members[field]; ok {
+				selected[field] = value
+			}
+		}
+		members = selected
+	}
+
+	return jsonAPIResource{
+		Type:       jsonAPIEventType,
+		ID:         event.ID.String(),
+		Attributes: members,
+	}, nil
+}
+
+// selfURL returns the absolute URL of the request, the self link of its document
+func selfURL(c echo.Context) string {
+	req := c.Request()
+	u := url.URL{
+		Scheme:   c.Scheme(),
+		Host:     req.Host,
+		Path:     req.URL.Path,
+		RawQuery: req.URL.RawQuery,
+	}
+	return u.String()
+}
+
+// eventURL returns the absolute URL of an event created by a POST to the collection
+func eventURL(c echo.Context, event *models.Event) string {
+	return c.Scheme() + "://" + c.Request().Host + eventLocation(c, event)
+}
+
+// respondJSONAPI writes a JSON:API document with its media type
+func respondJSONAPI(c echo.Context, status int, document jsonAPIDocument) error {
+	c.Response().Header().Set(echo.HeaderContentType, MIMEJSONAPI)
+	return c.JSON(status, document)
+}
+
+// respondEvent writes a single event, as protobuf or a JSON:API document when the client
+// asks for one
+func (s *Server) respondEvent(c echo.Context, status int, event *models.Event) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	if wantsProtobuf(c) {
+		message, err := marshalEvent(event)
+		return s.respondProtobuf(c, status, message, err)
+	}
+	if !wantsJSONAPI(c) {
+		return c.JSON(status, event)
+	}
+
+	resource, err := toJSONAPIResource(event, nil)
+	if err != nil {
+		s.logger.Error("Error encoding JSON:API resource", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	links := map[string]string{"self": selfURL(c)}
+	if status == http.StatusCreated {
+		// The request URL is the collection, the resource lives at its Location
+		links["self"] = eventURL(c, event)
+	}
+	return respondJSONAPI(c, status, jsonAPIDocument{Data: resource, Links: links})
+}
+
+// respondEventList writes a page of events as a JSON:API document with top-level self and,
+// when paginated, first, prev, next and last links, and the total in meta
+// A zero limit means the list isn't paginated.
+func (s *Server) respondEventList(c echo.Context, events []*models.Event, fields []string, limit, offset, total int) error {
+	resources := make([]jsonAPIResource, 0, len(events))
+	for _, event := range events {
+		resource, err := toJSONAPIResource(event, fields)
+		if err != nil {
+			s.logger.Error("Error encoding JSON:API resource", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to retrieve events",
+			})
+		}
+		resources = append(resources, resource)
+	}
+
+	links := map[string]string{"self": selfURL(c)}
+	if limit > 0 {
+		for _, link := range pageLinks(c, limit, offset, total) {
+			links[link.rel] = link.url
+		}
+	}
+	return respondJSONAPI(c, http.StatusOK, jsonAPIDocument{
+		Data:  resources,
+		Links: links,
+		Meta:  map[string]int{"total": total},
+	})
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// expensiveRetryAfter is the Retry-After, in seconds, of expensive requests turned away
+const expensiveRetryAfter = 5
+
+// limitExpensive is a middleware capping how many memory-heavy requests, like exports and
+// batches, run at once. Requests beyond the cap are answered with 503 and Retry-After
+// instead of waiting, so a burst of exports can't exhaust memory.
+func (s *Server) limitExpensive(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.expensive == nil {
+			return next(c)
+		}
+
+		select {
+		case s.expensive <- struct{}{}:
+		default:
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(expensiveRetryAfter))
+			return echo.NewHTTPError(http.StatusServiceUnavailable, map[string]string{
+				"error": "Too many expensive requests are running, try again later",
+			})
+		}
+		defer func() { <-s.expensive }()
+
+		return next(c)
+	}
+}
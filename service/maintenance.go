@@ -0,0 +1,142 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// VacuumResult is the response of POST /maintenance/vacuum, sizes in bytes
+type VacuumResult struct {
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+	Reclaimed  int64 `json:"reclaimed"`
+}
+
+// AnalyzeResult is the response of POST /maintenance/analyze
+type AnalyzeResult struct {
+	Optimized  bool  `json:"optimized"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// BackupResult is the response of POST /maintenance/backup, size in bytes
+type BackupResult struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// maintenanceBusy answers a maintenance request made while another one is still running
+func maintenanceBusy() error {
+	return echo.NewHTTPError(http.StatusConflict, map[string]string{
+		"error": "A maintenance task is already running",
+	})
+}
+
+// vacuumDatabase handles POST /maintenance/vacuum
+// Runs VACUUM to reclaim the space left by deleted and purged events and reports the size
+// of the database before and after. Writes wait while it runs, so only one runs at a time.
+func (s *Server) vacuumDatabase(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if !s.maintenance.TryLock() {
+		return maintenanceBusy()
+	}
+	defer s.maintenance.Unlock()
+
+	before, err := s.DB.DatabaseSize(ctx)
+	if err != nil {
+		s.logger.Error("Error getting database size", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to vacuum database",
+		})
+	}
+
+	if err := s.DB.Vacuum(ctx); err != nil {
+		s.logger.Error("Error vacuuming database", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to vacuum database",
+		})
+	}
+
+	after, err := s.DB.DatabaseSize(ctx)
+	if err != nil {
+		s.logger.Error("Error getting database size", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to vacuum database",
+		})
+	}
+
+	s.logger.Info("Vacuum finished", "reclaimed_bytes", before-after, "size_bytes", after)
+	return c.JSON(http.StatusOK, VacuumResult{
+		SizeBefore: before,
+		SizeAfter:  after,
+		Reclaimed:  before - after,
+	})
+}
+
+// analyzeDatabase handles POST /maintenance/analyze
+// Runs ANALYZE to refresh the query planner statistics as the data grows, and PRAGMA optimize
+// too with ?optimize=true, reporting how long it took
+func (s *Server) analyzeDatabase(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	optimize := c.QueryParam("optimize") == "true"
+
+	if !s.maintenance.TryLock() {
+		return maintenanceBusy()
+	}
+	defer s.maintenance.Unlock()
+
+	start := time.Now()
+	if err := s.DB.Analyze(ctx, optimize); err != nil {
+		s.logger.Error("Error analyzing database", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to analyze database",
+		})
+	}
+	elapsed := time.Since(start)
+
+	s.logger.Info("Analyze finished", "elapsed", elapsed)
+	return c.JSON(http.StatusOK, AnalyzeResult{
+		Optimized:  optimize,
+		DurationMs: elapsed.Milliseconds(),
+	})
+}
+
+// backupDatabase handles POST /maintenance/backup
+// Writes a consistent copy of the database to BACKUP_PATH with VACUUM INTO, replacing the
+// previous backup, and reports where it is and how large it is. Unlike copying the file this
+// is safe while writes are in flight. Answers 503 when BACKUP_PATH isn't set.
+func (s *Server) backupDatabase(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if s.backupPath == "" {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, map[string]string{
+			"error": "Backups are disabled, set BACKUP_PATH to enable them",
+		})
+	}
+
+	if !s.maintenance.TryLock() {
+		return maintenanceBusy()
+	}
+	defer s.maintenance.Unlock()
+
+	start := time.Now()
+	size, err := s.DB.Backup(ctx, s.backupPath)
+	if err != nil {
+		s.logger.Error("Error backing up database", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to back up database",
+		})
+	}
+	elapsed := time.Since(start)
+
+	s.logger.Info("Backed up database", "path", s.backupPath, "size_bytes", size, "elapsed", elapsed)
+	return c.JSON(http.StatusOK, BackupResult{
+		Path:       s.backupPath,
+		Size:       size,
+		DurationMs: elapsed.Milliseconds(),
+	})
+}
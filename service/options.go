@@ -0,0 +1,48 @@
+package service
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// registerOptions answers OPTIONS on every route with 204 and an Allow header listing the
+// methods its path supports, so generic REST clients can discover them. It runs after every
+// other route is registered.
+func (s *Server) registerOptions() {
+	methods := make(map[string][]string)
+	for _, route := range s.Echo.Routes() {
+		if route.Method == echo.RouteNotFound || route.Method == http.MethodOptions {
+			continue
+		}
+		methods[route.Path] = append(methods[route.Path], route.Method)
+	}
+
+	s.allowed = make(map[string]string, len(methods))
+	for path, pathMethods := range methods {
+		pathMethods = append(pathMethods, http.MethodOptions)
+		sort.Strings(pathMethods)
+		allow := strings.Join(pathMethods, ", ")
+
+		s.allowed[path] = allow
+		s.Echo.OPTIONS(path, func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderAllow, allow)
+			return c.NoContent(http.StatusNoContent)
+		})
+	}
+}
+
+// allowMethods hands the methods of the matched path to the CORS middleware, which answers
+// OPTIONS requests itself and takes them for the Allow and Access-Control-Allow-Methods headers
+func (s *Server) allowMethods(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Method == http.MethodOptions {
+			if allow, ok := s.allowed[c.Path()]; ok {
+				c.Set(echo.ContextKeyHeaderAllow, allow)
+			}
+		}
+		return next(c)
+	}
+}
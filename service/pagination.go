@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// MaxPageLimit is the largest page size a client may request
+const MaxPageLimit = 500
+
+// HeaderTotalCount carries the number of events matching a paginated query
+const HeaderTotalCount = "X-Total-Count"
+
+// parsePagination parses the optional limit and offset query parameters
+// A zero limit means the request isn't paginated
+func parsePagination(c echo.Context) (int, int, error) {
+	var limit, offset int
+
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxPageLimit {
+			return 0, 0, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("Invalid limit, expected a number between 1 and %d", MaxPageLimit),
+			})
+		}
+		limit = n
+	}
+
+	if value := c.QueryParam("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+				"error": "Invalid offset, expected a non-negative number",
+			})
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
+
+// pageURL returns the request URL with limit and offset replaced, keeping every other parameter
+func pageURL(c echo.Context, limit, offset int) string {
+	req := c.Request()
+
+	query := req.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	u := url.URL{
+		Scheme:   c.Scheme(),
+		Host:     req.Host,
+		Path:     req.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// pageLink is the URL of a page related to the current one, such as the next page
+type pageLink struct {
+	rel string
+	url string
+}
+
+// pageLinks returns the first, prev, next and last pages of a paginated response,
+// leaving out prev and next when there is no such page
+func pageLinks(c echo.Context, limit, offset, total int) []pageLink {
+	// Offset of the first item of the last page
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+
+	links := []pageLink{{"first", pageURL(c, limit, 0)}}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink{"prev", pageURL(c, limit, prev)})
+	}
+	if offset+limit < total {
+		links = append(links, pageLink{"next", pageURL(c, limit, offset+limit)})
+	}
+	return append(links, pageLink{"last", pageURL(c, limit, last)})
+}
+
+// setPageLinks sets the X-Total-Count header and an RFC 5988 Link header
+// with the first, prev, next and last pages of a paginated response
+func setPageLinks(c echo.Context, limit, offset, total int) {
+	var links []string
+	for _, link := range pageLinks(c, limit, offset, total) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, link.url, link.rel))
+	}
+
+	header := c.Response().Header()
+	header.Set(HeaderTotalCount, strconv.Itoa(total))
+	header.Set("Link", strings.Join(links, ", "))
+}
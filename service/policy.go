@@ -0,0 +1,136 @@
+package service
+
+import (
+	"challenge/config"
+	"challenge/models"
+	"challenge/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// ErrWindowLimitExceeded is returned when too many events already overlap the requested time
+var ErrWindowLimitExceeded = errors.New("too many events scheduled around the requested time")
+
+// ErrOwnerLimitExceeded is returned when the caller already has the maximum number of active events
+var ErrOwnerLimitExceeded = errors.New("the maximum number of active events for this user has been reached")
+
+// Policy holds the booking rules applied when creating events
+type Policy struct {
+	// WindowLimit is the maximum number of events that may overlap the window
+	// around a new event. Zero disables the check.
+	WindowLimit int
+	// Window is how far before the start and after the end of a new event
+	// existing events are counted
+	Window time.Duration
+	// DefaultDuration is used to compute end_time when a request omits it.
+	// Zero keeps end_time required.
+	DefaultDuration time.Duration
+	// MaxEventsPerOwner caps the active events of each non-admin user. Zero disables the check.
+	MaxEventsPerOwner int
+}
+
+// policyFromConfig builds the booking policy from the configuration
+func policyFromConfig(cfg config.Config) Policy {
+	return Policy{
+		WindowLimit:       cfg.WindowLimit,
+		Window:            time.Duration(cfg.Window),
+		DefaultDuration:   time.Duration(cfg.DefaultDuration),
+		MaxEventsPerOwner: cfg.MaxEventsPerOwner,
+	}
+}
+
+// applyDefaults fills in request fields the client omitted
+// An explicit end_time always wins over the default duration
+func (s *Server) applyDefaults(req *models.CreateEventRequest) {
+	if req.EndTime != "" || s.Policy.DefaultDuration == 0 {
+		return
+	}
+
+	startTime, err := s.rules.ParseTimestamp(req.StartTime)
+	if err != nil {
+		// Leave it to validation to report the bad start_time
+		return
+	}
+	req.EndTime = startTime.Add(s.Policy.DefaultDuration).Format(time.RFC3339Nano)
+}
+
+// policyCheck returns the check enforcing the booking policy on a new event created by p
+// inside the transaction inserting it
+func (s *Server) policyCheck(event *models.Event, p *Principal) repository.InsertCheck {
+	return func(ctx context.Context, counts repository.BookingCounts) error {
+		return s.checkPolicy(ctx, counts, event, p)
+	}
+}
+
+// checkPolicy verifies a new event created by p against the booking policy, counting the
+// bookings with counts
+// p is nil when authentication is disabled, which like admin keys skips the owner limit
+func (s *Server) checkPolicy(ctx context.Context, counts repository.BookingCounts, event *models.Event, p *Principal) error {
+	if err := s.checkOwnerLimit(ctx, counts, p); err != nil {
+		return err
+	}
+
+	if s.Policy.WindowLimit == 0 {
+		return nil
+	}
+
+	from := event.StartTime.Add(-s.Policy.Window)
+	to := event.EndTime.Add(s.Policy.Window)
+
+	count, err := counts.CountEventsInRange(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to check window limit: %w", err)
+	}
+
+	if count >= s.Policy.WindowLimit {
+		return ErrWindowLimitExceeded
+	}
+	return nil
+}
+
+// policyError converts a checkPolicy failure into the response of a refused creation,
+// 429 over the window limit and 403 over the owner limit, with failure as the 500 message
+func (s *Server) policyError(c echo.Context, err error, failure string) error {
+	if errors.Is(err, ErrWindowLimitExceeded) {
+		lang := requestLanguage(c)
+		c.Response().Header().Set("Content-Language", lang)
+		return echo.NewHTTPError(http.StatusTooManyRequests, map[string]string{
+			"error": localize(lang, codeWindowLimitExceeded, err.Error()),
+			"code":  codeWindowLimitExceeded,
+		})
+	}
+	if errors.Is(err, ErrOwnerLimitExceeded) {
+		lang := requestLanguage(c)
+		c.Response().Header().Set("Content-Language", lang)
+		return echo.NewHTTPError(http.StatusForbidden, map[string]string{
+			"error": localize(lang, codeOwnerLimitExceeded, err.Error()),
+			"code":  codeOwnerLimitExceeded,
+		})
+	}
+	s.logger.Error("Error checking booking policy", "error", err)
+	return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+		"error": failure,
+	})
+}
+
+// checkOwnerLimit verifies that p may create another event under MaxEventsPerOwner
+func (s *Server) checkOwnerLimit(ctx context.Context, counts repository.BookingCounts, p *Principal) error {
+	if s.Policy.MaxEventsPerOwner == 0 || p == nil || p.Admin {
+		return nil
+	}
+
+	count, err := counts.CountEventsByOwner(ctx, p.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check owner limit: %w", err)
+	}
+
+	if count >= s.Policy.MaxEventsPerOwner {
+		return ErrOwnerLimitExceeded
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"challenge/config"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// createConcurrently fires n identical-slot creates at once with key, returning how many got each status
+func createConcurrently(t *testing.T, s *Server, key string, n int) map[int]int {
+	t.Helper()
+
+	codes := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"title":"Booking %d","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`, i)
+			codes <- doWithKey(t, s, key, http.MethodPost, "/api/v1/events", body, nil).Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+	return counts
+}
+
+func TestWindowLimitHoldsUnderConcurrentCreates(t *testing.T) {
+	const limit = 5
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.WindowLimit = limit
+	})
+
+	counts := createConcurrently(t, s, "", limit+1)
+	if counts[http.StatusCreated] != limit || counts[http.StatusTooManyRequests] != 1 {
+		t.Errorf("statuses = %v, want %d created and 1 refused", counts, limit)
+	}
+}
+
+func TestOwnerLimitHoldsUnderConcurrentCreates(t *testing.T) {
+	const limit = 5
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"alice-key:alice"}
+		cfg.MaxEventsPerOwner = limit
+	})
+
+	counts := createConcurrently(t, s, "alice-key", limit+1)
+	if counts[http.StatusCreated] != limit || counts[http.StatusForbidden] != 1 {
+		t.Errorf("statuses = %v, want %d created and 1 refused", counts, limit)
+	}
+}
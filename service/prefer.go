@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"challenge/models"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// HeaderPrefer is the RFC 7240 request header carrying client preferences
+const HeaderPrefer = "Prefer"
+
+// HeaderPreferenceApplied tells the client which of its preferences were honored
+const HeaderPreferenceApplied = "Preference-Applied"
+
+// prefersReturn reports whether the request asks for return=value
+func prefersReturn(c echo.Context, value string) bool {
+	for _, header := range c.Request().Header.Values(HeaderPrefer) {
+		for _, preference := range strings.Split(header, ",") {
+			// Parameters after ; don't change the preference
+			token, _, _ := strings.Cut(preference, ";")
+			name, preferred, _ := strings.Cut(token, "=")
+			if strings.EqualFold(strings.TrimSpace(name), "return") &&
+				strings.EqualFold(strings.Trim(strings.TrimSpace(preferred), `"`), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// prefersMinimal reports whether the request asks for return=minimal
+func prefersMinimal(c echo.Context) bool {
+	return prefersReturn(c, "minimal")
+}
+
+// eventMembers returns the members of the JSON representation of an event
+func eventMembers(event *models.Event) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// changedFields returns the JSON members that differ between two states of an event, with
+// null for a member that was cleared. The id is always included to identify the event.
+func changedFields(previous, current *models.Event) (map[string]json.RawMessage, error) {
+	before, err := eventMembers(previous)
+	if err != nil {
+		return nil, err
+	}
+	after, err := eventMembers(current)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]json.RawMessage{"id": after["id"]}
+	for name, value := range after {
+		if !bytes.Equal(value, before[name]) {
+			changed[name] = value
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed[name] = json.RawMessage("null")
+		}
+	}
+	return changed, nil
+}
+
+// respondUpdated writes an event that was just updated with its ETag
+// With Prefer: return=changed the body only has the fields the update changed, including
+// updated_at, which the ETag is derived from; otherwise it is the full event
+func (s *Server) respondUpdated(c echo.Context, previous, current *models.Event) error {
+	c.Response().Header().Set("ETag", eventETag(current))
+	if prefersReturn(c, "changed") {
+		changed, err := changedFields(previous, current)
+		if err != nil {
+			s.logger.Error("Error computing changed fields", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to retrieve event",
+			})
+		}
+		c.Response().Header().Set(HeaderPreferenceApplied, "return=changed")
+		return c.JSON(http.StatusOK, changed)
+	}
+	return s.respondEvent(c, http.StatusOK, current)
+}
+
+// eventLocation returns the URL path of the event created by a POST to the collection,
+// built from the request path so any prefix the server is mounted under is kept
+// Actions on an event such as /events/:id/clone create a sibling of it in the collection.
+func eventLocation(c echo.Context, event *models.Event) string {
+	collection := c.Request().URL.Path
+	if c.Param("id") != "" {
+		collection = path.Dir(path.Dir(collection))
+	}
+	return path.Join(collection, event.ID.String())
+}
+
+// respondCreated writes an event that was just created, or whose creation was replayed,
+// with a Location header pointing at it
+// With Prefer: return=minimal the body is left empty, otherwise it is the full event.
+// With a Client-Id header the body maps it to the event's ID instead, see respondClientID.
+func (s *Server) respondCreated(c echo.Context, status int, event *models.Event) error {
+	c.Response().Header().Set(echo.HeaderLocation, eventLocation(c, event))
+	c.Response().Header().Set("ETag", eventETag(event))
+	if clientID := c.Request().Header.Get(HeaderClientID); clientID != "" {
+		return s.respondClientID(c, status, clientID, event)
+	}
+	if prefersMinimal(c) {
+		c.Response().Header().Set(HeaderPreferenceApplied, "return=minimal")
+		return c.NoContent(status)
+	}
+	return s.respondEvent(c, status, event)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"challenge/models"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MIMEProtobuf is the media type of protobuf responses, encoded as the messages of
+// proto/events.proto
+const MIMEProtobuf = "application/x-protobuf"
+
+// wantsProtobuf reports whether the request's Accept header asks for protobuf
+func wantsProtobuf(c echo.Context) bool {
+	for _, header := range c.Request().Header.Values(echo.HeaderAccept) {
+		for _, accepted := range strings.Split(header, ",") {
+			mediaType, _, err := mime.ParseMediaType(accepted)
+			if err == nil && mediaType == MIMEProtobuf {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendString appends a string field, leaving it out when empty as proto3 does
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendOptionalString(b, num, &s)
+}
+
+// appendOptionalString appends an optional string field, also when empty, unless s is nil
+func appendOptionalString(b []byte, num protowire.Number, s *string) []byte {
+	if s == nil {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, *s)
+}
+
+// appendVarint appends an integer field, leaving it out when zero
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// appendMessage appends an embedded message field
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+// appendTimestamp appends a google.protobuf.Timestamp field, which is always UTC
+func appendTimestamp(b []byte, num protowire.Number, t time.Time) []byte {
+	var ts []byte
+	ts = appendVarint(ts, 1, t.Unix())
+	ts = appendVarint(ts, 2, int64(t.Nanosecond()))
+	return appendMessage(b, num, ts)
+}
+
+// marshalRecurrence encodes a recurrence as the Recurrence message
+func marshalRecurrence(r *models.Recurrence) []byte {
+	var b []byte
+	b = appendString(b, 1, r.Frequency)
+	b = appendString(b, 2, r.TimeZone)
+	b = appendVarint(b, 3, int64(r.Count))
+	if r.Until != nil {
+		b = appendTimestamp(b, 4, *r.Until)
+	}
+	for _, excluded := range r.ExcludedDates {
+		b = appendTimestamp(b, 5, excluded)
+	}
+	return b
+}
+
+// marshalEvent encodes an event as the Event message
+func marshalEvent(event *models.Event) ([]byte, error) {
+	var b []byte
+	b = appendString(b, 1, event.ID.String())
+	b = appendString(b, 2, event.Title)
+	b = appendOptionalString(b, 3, event.Description)
+	b = appendTimestamp(b, 4, event.StartTime)
+	b = appendTimestamp(b, 5, event.EndTime)
+	b = appendTimestamp(b, 6, event.CreatedAt)
+	b = appendOptionalString(b, 7, event.CreatedBy)
+	b = appendTimestamp(b, 8, event.UpdatedAt)
+	b = appendString(b, 9, event.Status)
+	if event.DeletedAt != nil {
+		b = appendTimestamp(b, 10, *event.DeletedAt)
+	}
+	if event.Metadata != nil {
+		metadata, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		b = appendMessage(b, 11, metadata)
+	}
+	if event.Recurrence != nil {
+		b = appendMessage(b, 12, marshalRecurrence(event.Recurrence))
+	}
+	for _, link := range event.Links {
+		b = appendOptionalString(b, 13, &link)
+	}
+	b = appendOptionalString(b, 14, event.MeetingURL)
+	b = appendVarint(b, 15, int64(event.Priority))
+	for _, tag := range event.Tags {
+		b = appendOptionalString(b, 16, &tag)
+	}
+	b = appendString(b, 17, event.TimeZone)
+	return b, nil
+}
+
+// marshalEventList encodes a page of events as the EventList message
+func marshalEventList(events []*models.Event, total int) ([]byte, error) {
+	var b []byte
+	for _, event := range events {
+		message, err := marshalEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		b = appendMessage(b, 1, message)
+	}
+	return appendVarint(b, 2, int64(total)), nil
+}
+
+// respondProtobuf writes an encoded message, or logs why it couldn't be encoded
+func (s *Server) respondProtobuf(c echo.Context, status int, message []byte, err error) error {
+	if err != nil {
+		s.logger.Error("Error encoding protobuf response", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+	return c.Blob(status, MIMEProtobuf, message)
+}
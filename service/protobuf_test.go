@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bufio"
+	"challenge/models"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoDecl is a field as declared in proto/events.proto
+type protoDecl struct {
+	label  string // "optional", "repeated" or empty
+	typ    string
+	number int
+}
+
+var (
+	protoMessageLine = regexp.MustCompile(`^message (\w+) \{`)
+	protoFieldLine   = regexp.MustCompile(`^\s*(optional |repeated )?([\w.]+) (\w+) = (\d+);`)
+	protoRPCLine     = regexp.MustCompile(`^\s*rpc (\w+)\((\w+)\) returns \((\w+)\);`)
+)
+
+// readProtoFile parses the messages and RPCs of proto/events.proto, which only uses the
+// one-line declarations the patterns above match
+func readProtoFile(t *testing.T) (map[string]map[string]protoDecl, map[string][2]string) {
+	t.Helper()
+
+	f, err := os.Open("../proto/events.proto")
+	if err != nil {
+		t.Fatalf("opening events.proto: %v", err)
+	}
+	defer f.Close()
+
+	messages := make(map[string]map[string]protoDecl)
+	rpcs := make(map[string][2]string)
+	var current map[string]protoDecl
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := protoMessageLine.FindStringSubmatch(line); m != nil {
+			current = make(map[string]protoDecl)
+			messages[m[1]] = current
+			if line == "message "+m[1]+" {}" {
+				current = nil
+			}
+			continue
+		}
+		if m := protoRPCLine.FindStringSubmatch(line); m != nil {
+			rpcs[m[1]] = [2]string{m[2], m[3]}
+			continue
+		}
+		if line == "}" {
+			current = nil
+			continue
+		}
+		if m := protoFieldLine.FindStringSubmatch(line); m != nil && current != nil {
+			number, _ := strconv.Atoi(m[4])
+			current[m[3]] = protoDecl{label: strings.TrimSpace(m[1]), typ: m[2], number: number}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading events.proto: %v", err)
+	}
+	return messages, rpcs
+}
+
+// protoScalarKinds maps the scalar types events.proto uses to their kinds
+var protoScalarKinds = map[string]protoreflect.Kind{
+	"string": protoreflect.StringKind,
+	"int32":  protoreflect.Int32Kind,
+	"int64":  protoreflect.Int64Kind,
+	"bytes":  protoreflect.BytesKind,
+}
+
+func TestSchemaMatchesProtoFile(t *testing.T) {
+	messages, rpcs := readProtoFile(t)
+
+	if got, want := eventsSchema.Messages().Len(), len(messages); got != want {
+		t.Errorf("schema has %d messages, events.proto %d", got, want)
+	}
+	for name, fields := range messages {
+		md := schemaMessage(protoreflect.Name(name))
+		if md == nil {
+			t.Errorf("schema lacks message %s", name)
+			continue
+		}
+		if got, want := md.Fields().Len(), len(fields); got != want {
+			t.Errorf("%s: schema has %d fields, events.proto %d", name, got, want)
+		}
+		for field, decl := range fields {
+			fd := md.Fields().ByName(protoreflect.Name(field))
+			if fd == nil {
+				t.Errorf("%s: schema lacks field %s", name, field)
+				continue
+			}
+			if int(fd.Number()) != decl.number {
+				t.Errorf("%s.%s: number %d, events.proto %d", name, field, fd.Number(), decl.number)
+			}
+			if kind, ok := protoScalarKinds[decl.typ]; ok {
+				if fd.Kind() != kind {
+					t.Errorf("%s.%s: kind %v, events.proto %s", name, field, fd.Kind(), decl.typ)
+				}
+			} else {
+				want := decl.typ
+				if _, known := messages[want]; known {
+					want = "events.v1." + want
+				}
+				if fd.Kind() != protoreflect.MessageKind || string(fd.Message().FullName()) != want {
+					t.Errorf("%s.%s: type %v, events.proto %s", name, field, fd.Kind(), decl.typ)
+				}
+			}
+			if got := fd.Cardinality() == protoreflect.Repeated; got != (decl.label == "repeated") {
+				t.Errorf("%s.%s: repeated = %v, events.proto %q", name, field, got, decl.label)
+			}
+			if got := fd.HasOptionalKeyword(); got != (decl.label == "optional") {
+				t.Errorf("%s.%s: optional = %v, events.proto %q", name, field, got, decl.label)
+			}
+		}
+	}
+
+	methods := eventsSchema.Services().ByName("EventService").Methods()
+	if got, want := methods.Len(), len(rpcs); got != want {
+		t.Errorf("schema has %d methods, events.proto %d", got, want)
+	}
+	for name, types := range rpcs {
+		method := methods.ByName(protoreflect.Name(name))
+		if method == nil {
+			t.Errorf("schema lacks method %s", name)
+			continue
+		}
+		if method.Input().Name() != protoreflect.Name(types[0]) || method.Output().Name() != protoreflect.Name(types[1]) {
+			t.Errorf("%s: (%s) returns (%s), events.proto (%s) returns (%s)",
+				name, method.Input().Name(), method.Output().Name(), types[0], types[1])
+		}
+	}
+}
+
+// timestampValue returns the time held by a google.protobuf.Timestamp message
+func timestampValue(m protoreflect.Message) time.Time {
+	fields := m.Descriptor().Fields()
+	return time.Unix(m.Get(fields.ByName("seconds")).Int(), m.Get(fields.ByName("nanos")).Int()).UTC()
+}
+
+func TestMarshalEventDecodesThroughSchema(t *testing.T) {
+	description := ""
+	createdBy := "alice"
+	meetingURL := "https://meet.example.com/standup"
+	start := time.Date(2025, 3, 1, 9, 0, 0, 123456000, time.UTC)
+	until := start.AddDate(0, 0, 10)
+	deletedAt := start.Add(48 * time.Hour)
+	event := &models.Event{
+		ID:          uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f99a43"),
+		Title:       "Standup",
+		Description: &description,
+		StartTime:   start,
+		EndTime:     start.Add(15 * time.Minute),
+		TimeZone:    "+01:00",
+		CreatedAt:   start.Add(-time.Hour),
+		CreatedBy:   &createdBy,
+		UpdatedAt:   start.Add(-time.Minute),
+		Status:      models.StatusTentative,
+		DeletedAt:   &deletedAt,
+		Metadata:    map[string]interface{}{"source": "crm"},
+		Recurrence: &models.Recurrence{
+			Frequency:     models.FrequencyDaily,
+			TimeZone:      "Europe/Madrid",
+			Count:         7,
+			Until:         &until,
+			ExcludedDates: []time.Time{start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)},
+		},
+		Links:      []string{"https://example.com/agenda", "https://example.com/notes"},
+		MeetingURL: &meetingURL,
+		Priority:   3,
+		Tags:       []string{"team", "daily"},
+	}
+
+	data, err := marshalEventList([]*models.Event{event}, 42)
+	if err != nil {
+		t.Fatalf("marshalEventList: %v", err)
+	}
+	list := dynamicpb.NewMessage(schemaMessage("EventList"))
+	if err := proto.Unmarshal(data, list); err != nil {
+		t.Fatalf("decoding through the schema: %v", err)
+	}
+	if total := list.Get(list.Descriptor().Fields().ByName("total")).Int(); total != 42 {
+		t.Errorf("total = %d, want 42", total)
+	}
+	events := list.Get(list.Descriptor().Fields().ByName("events")).List()
+	if events.Len() != 1 {
+		t.Fatalf("decoded %d events, want 1", events.Len())
+	}
+
+	m := events.Get(0).Message()
+	fields := m.Descriptor().Fields()
+	get := func(name string) protoreflect.Value { return m.Get(fields.ByName(protoreflect.Name(name))) }
+	stringList := func(name string) []string {
+		var values []string
+		list := get(name).List()
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, list.Get(i).String())
+		}
+		return values
+	}
+
+	checks := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"id", get("id").String(), event.ID.String()},
+		{"title", get("title").String(), event.Title},
+		{"description set", m.Has(fields.ByName("description")), true},
+		{"description", get("description").String(), description},
+		{"start_time", timestampValue(get("start_time").Message()), event.StartTime},
+		{"end_time", timestampValue(get("end_time").Message()), event.EndTime},
+		{"created_at", timestampValue(get("created_at").Message()), event.CreatedAt},
+		{"created_by", get("created_by").String(), createdBy},
+		{"updated_at", timestampValue(get("updated_at").Message()), event.UpdatedAt},
+		{"status", get("status").String(), event.Status},
+		{"deleted_at", timestampValue(get("deleted_at").Message()), deletedAt},
+		{"metadata_json", string(get("metadata_json").Bytes()), `{"source":"crm"}`},
+		{"links", stringList("links"), event.Links},
+		{"meeting_url", get("meeting_url").String(), meetingURL},
+		{"priority", get("priority").Int(), int64(event.Priority)},
+		{"tags", stringList("tags"), event.Tags},
+		{"timezone", get("timezone").String(), event.TimeZone},
+	}
+
+	recurrence := get("recurrence").Message()
+	recurrenceFields := recurrence.Descriptor().Fields()
+	excluded := recurrence.Get(recurrenceFields.ByName("excluded_dates")).List()
+	var excludedDates []time.Time
+	for i := 0; i < excluded.Len(); i++ {
+		excludedDates = append(excludedDates, timestampValue(excluded.Get(i).Message()))
+	}
+	checks = append(checks, []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"recurrence.frequency", recurrence.Get(recurrenceFields.ByName("frequency")).String(), event.Recurrence.Frequency},
+		{"recurrence.timezone", recurrence.Get(recurrenceFields.ByName("timezone")).String(), event.Recurrence.TimeZone},
+		{"recurrence.count", recurrence.Get(recurrenceFields.ByName("count")).Int(), int64(event.Recurrence.Count)},
+		{"recurrence.until", timestampValue(recurrence.Get(recurrenceFields.ByName("until")).Message()), until},
+		{"recurrence.excluded_dates", excludedDates, event.Recurrence.ExcludedDates},
+	}...)
+
+	for _, c := range checks {
+		if !equalValues(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.field, c.got, c.want)
+		}
+	}
+	if unknown := m.GetUnknown(); len(unknown) > 0 {
+		t.Errorf("Event has %d bytes of fields the schema doesn't declare", len(unknown))
+	}
+}
+
+// equalValues compares the decoded and expected values of a field
+func equalValues(got, want interface{}) bool {
+	switch want := want.(type) {
+	case time.Time:
+		return got.(time.Time).Equal(want)
+	case []time.Time:
+		got := got.([]time.Time)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if !got[i].Equal(want[i]) {
+				return false
+			}
+		}
+		return true
+	case []string:
+		got := got.([]string)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return got == want
+}
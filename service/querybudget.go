@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"challenge/repository"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// HeaderQueryCount is the response header carrying the database operations of a request in dev mode
+const HeaderQueryCount = "X-Query-Count"
+
+// bufferedResponse holds a response back so it can still be replaced by an error
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header returns the headers of the held response
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+// WriteHeader records the status of the held response
+func (b *bufferedResponse) WriteHeader(code int) {
+	b.status = code
+}
+
+// Write appends to the held body
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// writeHeld writes a held response out, leaving the response untouched when nothing was written
+func writeHeld(res *echo.Response, held *bufferedResponse) error {
+	for name, values := range held.header {
+		res.Header()[name] = values
+	}
+	if held.status == 0 {
+		return nil
+	}
+	res.WriteHeader(held.status)
+	_, err := res.Write(held.body.Bytes())
+	return err
+}
+
+// countQueries is a middleware counting the database operations of each request against
+// the query budget, to catch handlers that query once per item. Requests over budget are
+// logged. In dev mode they fail with 500 instead, and every response carries the count in
+// X-Query-Count; responses are held back until the handler returns for that, except the
+// event stream, which is only counted.
+func (s *Server) countQueries(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.queryBudget == 0 && !s.devMode {
+			return next(c)
+		}
+
+		ctx, counter := repository.WithQueryCounter(c.Request().Context())
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		if !s.devMode || c.Path() == s.basePath+"/api/v1/events/stream" {
+			err := next(c)
+			s.checkQueryBudget(c, counter.Count())
+			return err
+		}
+
+		res := c.Response()
+		held := &bufferedResponse{header: res.Header().Clone()}
+		c.SetResponse(echo.NewResponse(held, s.Echo))
+
+		// Write the error response into the held one, the outer error handler skips
+		// responses that are already committed
+		err := next(c)
+		if err != nil {
+			c.Error(err)
+		}
+		c.SetResponse(res)
+
+		count := counter.Count()
+		res.Header().Set(HeaderQueryCount, strconv.FormatInt(count, 10))
+		if !s.checkQueryBudget(c, count) {
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("The request ran %d database queries, over the budget of %d", count, s.queryBudget),
+			})
+		}
+
+		if err := writeHeld(res, held); err != nil {
+			return err
+		}
+		return err
+	}
+}
+
+// checkQueryBudget logs a request that ran more than the budgeted count of database
+// operations and reports whether it stayed within budget
+func (s *Server) checkQueryBudget(c echo.Context, count int64) bool {
+	if s.queryBudget == 0 || count <= int64(s.queryBudget) {
+		return true
+	}
+
+	req := c.Request()
+	s.logger.Warn("Request over the query budget", "method", req.Method, "path", c.Path(), "queries", count, "budget", s.queryBudget)
+	return false
+}
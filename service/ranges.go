@@ -0,0 +1,69 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+)
+
+// byteRange is a single range of a Range header, -1 marks an omitted bound
+// A range with only an end, like bytes=-500, asks for that many trailing bytes
+type byteRange struct {
+	start, end int64
+}
+
+// parseByteRange parses a Range header asking for a single byte range
+// Headers with other units or several ranges aren't supported and report false,
+// in which case the whole representation is served
+func parseByteRange(header string) (byteRange, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, false
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok || (first == "" && last == "") {
+		return byteRange{}, false
+	}
+
+	r := byteRange{start: -1, end: -1}
+	var err error
+	if first != "" {
+		if r.start, err = strconv.ParseInt(first, 10, 64); err != nil || r.start < 0 {
+			return byteRange{}, false
+		}
+	}
+	if last != "" {
+		if r.end, err = strconv.ParseInt(last, 10, 64); err != nil || r.end < 0 {
+			return byteRange{}, false
+		}
+	}
+	if r.start >= 0 && r.end >= 0 && r.end < r.start {
+		return byteRange{}, false
+	}
+	return r, true
+}
+
+// resolve returns the first and last byte of the range in a representation of size bytes
+// It reports false when the range is unsatisfiable
+func (r byteRange) resolve(size int64) (int64, int64, bool) {
+	if r.start < 0 {
+		// Suffix range
+		if r.end == 0 || size == 0 {
+			return 0, 0, false
+		}
+		start := size - r.end
+		if start < 0 {
+			start = 0
+		}
+		return start, size - 1, true
+	}
+
+	if r.start >= size {
+		return 0, 0, false
+	}
+	end := r.end
+	if end < 0 || end >= size {
+		end = size - 1
+	}
+	return r.start, end, true
+}
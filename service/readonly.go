@@ -0,0 +1,47 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// codeReadOnly is the error code of writes rejected in read-only mode
+const codeReadOnly = "READ_ONLY"
+
+// readOnlyReads are the routes that use a write method but only read
+var readOnlyReads = map[string]bool{
+	"/api/v1/events/batch-get":   true,
+	"/api/v1/maintenance/backup": true,
+}
+
+// validatesOnly reports whether a request to path only validates an event instead of
+// creating it, which is decided by its query and not its route
+func validatesOnly(c echo.Context, path string) bool {
+	return path == "/api/v1/events" && c.Request().Method == http.MethodPost && c.QueryParam("validate_only") == "true"
+}
+
+// rejectWrites is a middleware answering writes with 503 while the server is read-only
+// Reads, identified by their HTTP method, keep working
+func (s *Server) rejectWrites(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !s.readOnly {
+			return next(c)
+		}
+
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(c)
+		}
+		path := strings.TrimPrefix(c.Path(), s.basePath)
+		if readOnlyReads[path] || validatesOnly(c, path) {
+			return next(c)
+		}
+
+		return echo.NewHTTPError(http.StatusServiceUnavailable, map[string]string{
+			"error": "The service is in read-only mode for maintenance, try again later",
+			"code":  codeReadOnly,
+		})
+	}
+}
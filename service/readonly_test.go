@@ -0,0 +1,25 @@
+package service
+
+import (
+	"challenge/config"
+	"net/http"
+	"testing"
+)
+
+func TestReadOnlyAllowsValidateOnly(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.ReadOnly = true
+	})
+
+	const body = `{"title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`
+
+	if rec := do(t, s, http.MethodPost, "/api/v1/events?validate_only=true", body, nil); rec.Code != http.StatusOK {
+		t.Errorf("validate_only: status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec := do(t, s, http.MethodPost, "/api/v1/events?validate_only=false", body, nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("create: status %d, want 503: %s", rec.Code, rec.Body)
+	}
+	if rec := do(t, s, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("create: status %d, want 503: %s", rec.Code, rec.Body)
+	}
+}
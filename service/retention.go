@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// cleanup removes the events that ended more than the retention period ago
+func (s *Server) cleanup(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.retention)
+
+	removed, err := s.DB.PurgeEventsEndedBefore(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Cleanup removed old events", "removed", removed, "ended_before", cutoff.Format(time.RFC3339))
+	if removed > 0 {
+		if err := s.reconcileCount(ctx); err != nil {
+			s.logger.Error("Error reconciling event count", "error", err)
+		}
+	}
+	return nil
+}
+
+// runCleanup runs the cleanup every interval until ctx is done
+func (s *Server) runCleanup(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Removing old events periodically", "retention", s.retention, "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.cleanup(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Error cleaning up events", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
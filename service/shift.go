@@ -0,0 +1,264 @@
+package service
+
+import (
+	"challenge/models"
+	"challenge/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+)
+
+// shiftEvents handles POST /events/shift
+// Moves every requested event, and the until bound and excluded dates of its recurrence, by
+// the same offset in a single transaction. The shifted events are checked for overlaps with
+// the events left in place and against the booking policy first; if any would conflict
+// nothing is changed and 409 lists the conflicting events at their shifted times.
+func (s *Server) shiftEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.ShiftRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+
+	offset, err := time.ParseDuration(req.Offset)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Invalid offset %q, expected a duration such as 24h", req.Offset),
+		})
+	}
+
+	ids, err := parseBatchIDs(req.IDs)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.DB.GetEventsByIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error("Error getting events by IDs", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve events",
+		})
+	}
+	if len(events) < len(ids) {
+		return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+			"error": "Event not found",
+		})
+	}
+
+	for _, event := range events {
+		if err := authorizeWrite(c, event); err != nil {
+			return err
+		}
+		start := event.StartTime.Add(offset)
+		event.Recurrence = event.Recurrence.MoveTo(event.StartTime, start)
+		event.StartTime = start
+		event.EndTime = event.EndTime.Add(offset)
+
+		// Excluded dates and the until bound are tied to the start time
+		if event.Recurrence != nil {
+			if err := event.Recurrence.Validate(event.StartTime); err != nil {
+				return err
+			}
+		}
+	}
+
+	conflicts, err := s.shiftConflicts(ctx, events, ids)
+	if err != nil {
+		s.logger.Error("Error checking shifted events", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to shift events",
+		})
+	}
+	if len(conflicts) > 0 {
+		return c.JSON(http.StatusConflict, models.ShiftResponse{
+			Events:    []*models.Event{},
+			Conflicts: conflicts,
+		})
+	}
+
+	if err := s.DB.ShiftEvents(ctx, events); err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error shifting events", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to shift events",
+		})
+	}
+
+	for _, event := range events {
+		s.Hub.Publish(EventChange{Type: ChangeUpdated, Event: event})
+	}
+
+	return c.JSON(http.StatusOK, models.ShiftResponse{
+		Events:    events,
+		Conflicts: []*models.Event{},
+	})
+}
+
+// shiftConflicts returns the shifted events that would overlap events left in place, unless
+// they are cancelled, or exceed the window limit
+// Stored copies of the shifted events are left out, the shifted events themselves are
+// counted at their new times. Shifting by the same offset keeps how they overlap each other.
+func (s *Server) shiftConflicts(ctx context.Context, events []*models.Event, ids []uuid.UUID) ([]*models.Event, error) {
+	var conflicts []*models.Event
+	for _, event := range events {
+		if event.Status != models.StatusCancelled {
+			overlapping, err := s.DB.FindOverlappingEvents(ctx, event.StartTime, event.EndTime, ids...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find overlapping events: %w", err)
+			}
+			if len(overlapping) > 0 {
+				conflicts = append(conflicts, event)
+				continue
+			}
+		}
+
+		if s.Policy.WindowLimit == 0 {
+			continue
+		}
+
+		from := event.StartTime.Add(-s.Policy.Window)
+		to := event.EndTime.Add(s.Policy.Window)
+
+		count, err := s.DB.CountEventsInRange(ctx, from, to, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check window limit: %w", err)
+		}
+
+		for _, other := range events {
+			if other != event && other.Status != models.StatusCancelled && other.StartTime.Before(to) && other.EndTime.After(from) {
+				count++
+			}
+		}
+
+		if count >= s.Policy.WindowLimit {
+			conflicts = append(conflicts, event)
+		}
+	}
+	return conflicts, nil
+}
+
+// rescheduleEvent handles POST /events/:id/reschedule
+// Moves a single event to new start and end times without touching its other fields.
+// Overlaps with other events answer 409 listing them, cancelled events excepted, and the
+// window limit is checked with the event counted at its new times. If-Match and Prefer
+// work as they do for updates.
+func (s *Server) rescheduleEvent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid UUID format",
+		})
+	}
+
+	var req models.RescheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request payload",
+		})
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	event, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		s.logger.Error("Error getting event by ID", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	if err := authorizeWrite(c, event); err != nil {
+		return err
+	}
+
+	previous := *event
+	event.StartTime, event.EndTime = req.Times()
+
+	// Excluded dates and the until bound are tied to the start time
+	if event.Recurrence != nil {
+		if err := event.Recurrence.Validate(event.StartTime); err != nil {
+			return err
+		}
+	}
+
+	if event.Status != models.StatusCancelled {
+		overlapping, err := s.DB.FindOverlappingEvents(ctx, event.StartTime, event.EndTime, id)
+		if err != nil {
+			s.logger.Error("Error finding overlapping events", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to reschedule event",
+			})
+		}
+		if len(overlapping) > 0 {
+			return echo.NewHTTPError(http.StatusConflict, map[string]interface{}{
+				"error":     "The new times overlap other events",
+				"conflicts": overlapping,
+			})
+		}
+	}
+
+	conflicts, err := s.shiftConflicts(ctx, []*models.Event{event}, []uuid.UUID{id})
+	if err != nil {
+		s.logger.Error("Error checking rescheduled event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to reschedule event",
+		})
+	}
+	if len(conflicts) > 0 {
+		lang := requestLanguage(c)
+		c.Response().Header().Set("Content-Language", lang)
+		return echo.NewHTTPError(http.StatusTooManyRequests, map[string]string{
+			"error": localize(lang, codeWindowLimitExceeded, ErrWindowLimitExceeded.Error()),
+			"code":  codeWindowLimitExceeded,
+		})
+	}
+
+	if err := s.DB.RescheduleEvent(ctx, id, event.StartTime, event.EndTime, ifMatchPrecondition(c)); err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, map[string]string{
+				"error": "Event not found",
+			})
+		}
+		if errors.Is(err, repository.ErrPreconditionFailed) {
+			return echo.NewHTTPError(http.StatusPreconditionFailed, map[string]string{
+				"error": "The event changed since it was read, fetch it again before rescheduling",
+			})
+		}
+		s.logger.Error("Error rescheduling event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to reschedule event",
+		})
+	}
+
+	updated, err := s.DB.GetEventByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error getting rescheduled event", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve event",
+		})
+	}
+
+	s.Hub.Publish(EventChange{Type: ChangeUpdated, Event: updated})
+
+	return s.respondUpdated(c, &previous, updated)
+}
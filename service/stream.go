@@ -0,0 +1,55 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// keepAliveInterval is how often a comment is sent to idle stream clients
+// so proxies don't close the connection
+const keepAliveInterval = 15 * time.Second
+
+// streamEvents handles GET /events/stream
+// Pushes create/update/delete notifications as Server-Sent Events until the client disconnects
+func (s *Server) streamEvents(c echo.Context) error {
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	changes := s.Hub.Subscribe()
+	defer s.Hub.Unsubscribe(changes)
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	done := c.Request().Context().Done()
+	for {
+		select {
+		case <-done:
+			// Client went away
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		case change := <-changes:
+			data, err := json.Marshal(change)
+			if err != nil {
+				s.logger.Error("Error encoding event change", "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Type, data); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"challenge/models"
+	"net/http"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// SyncItem is an event changed since the last sync, flagged when it was deleted
+type SyncItem struct {
+	*models.Event
+	Deleted bool `json:"deleted"`
+}
+
+// SyncResponse is returned by the delta-sync endpoint
+type SyncResponse struct {
+	Changes []SyncItem `json:"changes"`
+	// ServerTime is the value the client should send as since on its next sync
+	ServerTime time.Time `json:"server_time"`
+}
+
+// listChanges handles GET /events/changes
+// Returns events created, updated or deleted since the given time, ordered by updated_at
+func (s *Server) listChanges(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	since, err := s.rules.ParseTimestamp(c.QueryParam("since"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]string{
+			"error": "Invalid or missing since, expected ISO 8601 format",
+		})
+	}
+
+	// Taken before querying so changes made during the query are picked up next time
+	serverTime := time.Now().UTC()
+
+	events, err := s.DB.GetChangesSince(ctx, since)
+	if err != nil {
+		s.logger.Error("Error getting changes", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve changes",
+		})
+	}
+
+	changes := make([]SyncItem, 0, len(events))
+	for _, event := range events {
+		changes = append(changes, SyncItem{
+			Event:   event,
+			Deleted: event.DeletedAt != nil,
+		})
+	}
+
+	return c.JSON(http.StatusOK, SyncResponse{
+		Changes:    changes,
+		ServerTime: serverTime,
+	})
+}
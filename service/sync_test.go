@@ -0,0 +1,85 @@
+package service
+
+import (
+	"challenge/config"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// syncChanges lists the changes since the given server time, returning the next one
+func syncChanges(t *testing.T, s *Server, since time.Time) ([]SyncItem, time.Time) {
+	t.Helper()
+
+	var resp SyncResponse
+	path := "/api/v1/events/changes?since=" + url.QueryEscape(since.Format(time.RFC3339Nano))
+	if rec := doWithKey(t, s, "admin-key", http.MethodGet, path, "", &resp); rec.Code != http.StatusOK {
+		t.Fatalf("changes: status %d: %s", rec.Code, rec.Body)
+	}
+	return resp.Changes, resp.ServerTime
+}
+
+func TestSyncReportsRestoredEvents(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.APIKeys = []string{"admin-key:alice:admin"}
+	})
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	rec := doWithKey(t, s, "admin-key", http.MethodPost, "/api/v1/events",
+		`{"title":"Planning","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T11:00:00Z"}`, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	path := "/api/v1/events/" + created.ID
+	rec = doWithKey(t, s, "admin-key", http.MethodGet, path, "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get: status %d: %s", rec.Code, rec.Body)
+	}
+	backup := "[" + rec.Body.String() + "]"
+
+	restore := func(step string) {
+		t.Helper()
+		if rec := doWithKey(t, s, "admin-key", http.MethodPost, "/api/v1/events/restore", backup, nil); rec.Code != http.StatusOK {
+			t.Fatalf("%s: restore: status %d: %s", step, rec.Code, rec.Body)
+		}
+	}
+	wantChange := func(step string, changes []SyncItem, title string, deleted bool) {
+		t.Helper()
+		if len(changes) != 1 || changes[0].ID.String() != created.ID {
+			t.Fatalf("%s: changes = %+v, want the event", step, changes)
+		}
+		if changes[0].Title != title || changes[0].Deleted != deleted {
+			t.Errorf("%s: change has title %q, deleted %v, want %q, %v", step, changes[0].Title, changes[0].Deleted, title, deleted)
+		}
+	}
+
+	// An update, then a restore of the older copy, which clients must see as a change
+	rec = doWithKey(t, s, "admin-key", http.MethodPatch, path, `{"title":"Planning v2"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: status %d: %s", rec.Code, rec.Body)
+	}
+	changes, since := syncChanges(t, s, time.Time{})
+	wantChange("after the update", changes, "Planning v2", false)
+	restore("over the update")
+	changes, since = syncChanges(t, s, since)
+	wantChange("after restoring over the update", changes, "Planning", false)
+
+	// A deletion, then a restore bringing the event back
+	if rec := doWithKey(t, s, "admin-key", http.MethodDelete, path, "", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d: %s", rec.Code, rec.Body)
+	}
+	changes, since = syncChanges(t, s, since)
+	wantChange("after the deletion", changes, "Planning", true)
+	restore("over the deletion")
+	changes, since = syncChanges(t, s, since)
+	wantChange("after restoring over the deletion", changes, "Planning", false)
+
+	// Restoring again changes nothing
+	restore("again")
+	if changes, _ = syncChanges(t, s, since); len(changes) != 0 {
+		t.Errorf("after restoring again: changes = %+v, want none", changes)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"challenge/models"
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// listTags handles GET /tags
+// Returns every tag in use with the number of events carrying it, most used first
+func (s *Server) listTags(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	tags, err := s.DB.GetTags(ctx)
+	if err != nil {
+		s.logger.Error("Error getting tags", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve tags",
+		})
+	}
+
+	// Return empty array instead of null if no tags
+	if tags == nil {
+		tags = []models.TagCount{}
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
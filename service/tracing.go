@@ -0,0 +1,56 @@
+package service
+
+import (
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the request spans
+var tracer = otel.Tracer("challenge/service")
+
+// traceRequests is a middleware starting a span per request, continuing the trace of the
+// incoming traceparent header when there is one
+// Handlers pass the request context to the repository, so database spans become its children
+func traceRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		route := c.Path()
+		ctx, span := tracer.Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", req.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if id := c.Param("id"); id != "" {
+			span.SetAttributes(attribute.String("event.id", id))
+		}
+
+		c.SetRequest(req.WithContext(ctx))
+
+		// Write the error response here so the span records the final status,
+		// the error handler skips responses that are already committed
+		err := next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return err
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"challenge/config"
+	"challenge/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	echo "github.com/labstack/echo/v4"
+)
+
+// selfValidator is implemented by requests with rules the validate tags can't express
+type selfValidator interface {
+	Validate(rules models.Rules) error
+}
+
+// requestValidator implements echo.Validator using struct tag rules, followed by the
+// request's own Validate method when it has one
+type requestValidator struct {
+	validate *validator.Validate
+	// rules are the configurable rules passed to the requests' Validate methods
+	rules models.Rules
+}
+
+// rulesFromConfig builds the configurable validation rules from the configuration
+func rulesFromConfig(cfg config.Config) models.Rules {
+	return models.Rules{
+		AllowZeroDuration: cfg.AllowZeroDuration,
+		StrictTimestamps:  cfg.StrictTimeParsing,
+		MinYear:           cfg.MinEventYear,
+		MaxYear:           cfg.MaxEventYear,
+	}
+}
+
+// newRequestValidator creates a validator applying rules and reporting fields by their JSON name
+func newRequestValidator(rules models.Rules) *requestValidator {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return &requestValidator{validate: v, rules: rules}
+}
+
+// Validate checks a request, returning models.FieldErrors when a validate tag fails
+// or the error of the request's own Validate method
+func (v *requestValidator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	if err == nil {
+		if sv, ok := i.(selfValidator); ok {
+			return sv.Validate(v.rules)
+		}
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	fieldErrs := make(models.FieldErrors, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		code, message := models.CodeInvalidField, fmt.Sprintf("%s is invalid", fe.Field())
+		if known, ok := models.TagErrors[fe.Field()+"."+fe.Tag()]; ok {
+			code, message = known.Code, known.Message
+		}
+		fieldErrs = append(fieldErrs, models.FieldError{
+			Field:   fe.Field(),
+			Code:    code,
+			Message: message,
+		})
+	}
+	return fieldErrs
+}
+
+// isValidationError reports whether err is a validation failure returned by the validator
+func isValidationError(err error) bool {
+	var fieldErrs models.FieldErrors
+	var validationErr *models.ValidationError
+	return errors.As(err, &fieldErrs) || errors.As(err, &validationErr)
+}
+
+// toFieldErrors lists the errors of a validation failure
+// Errors that aren't about a single field are returned without one
+func toFieldErrors(err error) models.FieldErrors {
+	var fieldErrs models.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		return fieldErrs
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return models.FieldErrors{{Code: validationErr.Code, Message: validationErr.Message}}
+	}
+
+	if errors.Is(err, ErrWindowLimitExceeded) {
+		return models.FieldErrors{{Code: codeWindowLimitExceeded, Message: err.Error()}}
+	}
+
+	if errors.Is(err, ErrOwnerLimitExceeded) {
+		return models.FieldErrors{{Code: codeOwnerLimitExceeded, Message: err.Error()}}
+	}
+
+	return models.FieldErrors{{Code: models.CodeInvalidField, Message: err.Error()}}
+}
+
+// validationError converts a validation failure into a 400 response with the
+// message in the language the client asked for
+// Field-level errors are listed under "fields" next to the usual "error" message and code
+func validationError(c echo.Context, err error) error {
+	lang := requestLanguage(c)
+	c.Response().Header().Set("Content-Language", lang)
+
+	errs := localizeFieldErrors(lang, toFieldErrors(err))
+	body := map[string]interface{}{
+		"error": errs[0].Message,
+		"code":  errs[0].Code,
+	}
+	if errs[0].Field != "" {
+		body["fields"] = errs
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, body)
+}
+
+// itemValidationError converts the validation failure of the i-th item of a bulk request
+// into a 400 response whose message and "index" point at the item
+func itemValidationError(c echo.Context, i int, err error) error {
+	lang := requestLanguage(c)
+	c.Response().Header().Set("Content-Language", lang)
+
+	fe := localizeFieldErrors(lang, toFieldErrors(err))[0]
+	return echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+		"error": fmt.Sprintf("event %d: %s", i, fe.Message),
+		"code":  fe.Code,
+		"index": i,
+	})
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"challenge/config"
+	"net/http"
+	"testing"
+)
+
+func TestServersKeepTheirOwnZeroDurationRule(t *testing.T) {
+	allowing := newTestServer(t, nil)
+	rejecting := newTestServer(t, func(cfg *config.Config) {
+		cfg.AllowZeroDuration = false
+	})
+
+	body := `{"title":"Reminder","start_time":"2025-03-01T10:00:00Z","end_time":"2025-03-01T10:00:00Z"}`
+	if rec := do(t, rejecting, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("server rejecting zero durations: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := do(t, allowing, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusCreated {
+		t.Errorf("server allowing zero durations: status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+}
+
+func TestServersKeepTheirOwnYearRange(t *testing.T) {
+	wide := newTestServer(t, nil)
+	narrow := newTestServer(t, func(cfg *config.Config) {
+		cfg.MinEventYear, cfg.MaxEventYear = 2020, 2030
+	})
+
+	body := `{"title":"Launch","start_time":"2040-03-01T10:00:00Z","end_time":"2040-03-01T11:00:00Z"}`
+	if rec := do(t, narrow, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("server up to 2030: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := do(t, wide, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusCreated {
+		t.Errorf("server up to 2100: status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+}
+
+func TestServersKeepTheirOwnTimestampParsing(t *testing.T) {
+	lenient := newTestServer(t, nil)
+	strict := newTestServer(t, func(cfg *config.Config) {
+		cfg.StrictTimeParsing = true
+	})
+
+	body := `{"title":"Standup","start_time":"2025-03-01 10:00:00","end_time":"2025-03-01 10:15:00"}`
+	if rec := do(t, strict, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("strict server: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := do(t, lenient, http.MethodPost, "/api/v1/events", body, nil); rec.Code != http.StatusCreated {
+		t.Errorf("lenient server: status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	path := "/api/v1/events/availability?start=2025-03-02T10:00:00&end=2025-03-02T11:00:00"
+	if rec := do(t, strict, http.MethodGet, path, "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("strict server query: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := do(t, lenient, http.MethodGet, path, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("lenient server query: status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"challenge/repository"
+	"net/http"
+	"runtime"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// Version is the application version, set at build time with
+// -ldflags "-X challenge/service.Version=v1.2.3"
+var Version = "dev"
+
+// VersionResponse reports what is deployed
+type VersionResponse struct {
+	// Version is the application version
+	Version string `json:"version"`
+	// GoVersion is the Go runtime the binary was built with
+	GoVersion string `json:"go_version"`
+	// SchemaVersion is the last migration applied to the database
+	SchemaVersion int `json:"schema_version"`
+	// LatestSchemaVersion is the last migration this build knows, equal to SchemaVersion
+	// once every pending migration is applied
+	LatestSchemaVersion int `json:"latest_schema_version"`
+}
+
+// getVersion handles GET /version
+func (s *Server) getVersion(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	schemaVersion, err := s.DB.SchemaVersion(ctx)
+	if err != nil {
+		s.logger.Error("Error getting schema version", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to retrieve schema version",
+		})
+	}
+
+	return c.JSON(http.StatusOK, VersionResponse{
+		Version:             Version,
+		GoVersion:           runtime.Version(),
+		SchemaVersion:       schemaVersion,
+		LatestSchemaVersion: repository.LatestSchemaVersion(),
+	})
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotTimeExpression is returned by ParseTimeExpression for values not starting with
+// one of its keywords, which are likely meant as timestamps instead
+var ErrNotTimeExpression = errors.New("not a relative time expression")
+
+// timeKeywords resolve the base of a relative time expression against now
+var timeKeywords = map[string]func(now time.Time) time.Time{
+	"now":      func(now time.Time) time.Time { return now },
+	"today":    startOfDay,
+	"tomorrow": func(now time.Time) time.Time { return startOfDay(now).AddDate(0, 0, 1) },
+}
+
+// startOfDay returns midnight of the calendar day of t in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// ParseTimeExpression resolves a relative time expression like now+7d against now
+// An expression is now, today or tomorrow, optionally followed by + or - and an offset.
+// Offsets are Go durations like 90m, or whole days and weeks like 7d and 2w, which move by
+// calendar days so they keep the wall-clock time across DST. today and tomorrow are
+// midnight in the location of now. A space is read as +, since an unescaped + in a query
+// string decodes to one. Values not starting with a keyword return ErrNotTimeExpression.
+func ParseTimeExpression(expr string, now time.Time) (time.Time, error) {
+	expr = strings.ReplaceAll(expr, " ", "+")
+
+	keyword, offset := expr, ""
+	if i := strings.IndexAny(expr, "+-"); i >= 0 {
+		keyword, offset = expr[:i], expr[i:]
+	}
+
+	resolve, ok := timeKeywords[keyword]
+	if !ok {
+		return time.Time{}, ErrNotTimeExpression
+	}
+	t := resolve(now)
+	if offset == "" {
+		return t, nil
+	}
+
+	sign := 1
+	if offset[0] == '-' {
+		sign = -1
+	}
+	amount := offset[1:]
+	if amount == "" {
+		return time.Time{}, fmt.Errorf("missing offset after %q in %q", offset[:1], expr)
+	}
+
+	for suffix, days := range map[string]int{"d": 1, "w": 7} {
+		if value, ok := strings.CutSuffix(amount, suffix); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return time.Time{}, fmt.Errorf("invalid offset %q in %q, expected a whole number of days or weeks like 7d or 2w", amount, expr)
+			}
+			return t.AddDate(0, 0, sign*n*days), nil
+		}
+	}
+
+	d, err := time.ParseDuration(amount)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid offset %q in %q, expected a duration like 90m, 7d or 2w", amount, expr)
+	}
+	return t.Add(time.Duration(sign) * d), nil
+}
@@ -1,24 +1,43 @@
 package utils
 
 import (
+	"errors"
 	"time"
 )
 
-// parseTimestamp parses a timestamp string in ISO 8601 format
-// Supports formats: RFC3339 (2006-01-02T15:04:05Z07:00) and similar variations
-func ParseTimestamp(timestamp string) (time.Time, error) {
-	// Try parsing with different time formats
-	formats := []string{
-		time.RFC3339,                  // 2006-01-02T15:04:05Z07:00
-		time.RFC3339Nano,              // 2006-01-02T15:04:05.999999999Z07:00
-		"2006-01-02T15:04:05",         // Without timezone
-		"2006-01-02 15:04:05",         // Space separator
-		"2006-01-02T15:04:05.000Z",    // With milliseconds
-		"2006-01-02T15:04:05.000000Z", // With microseconds
+// ErrMissingOffset is returned by ParseStrictTimestamp for timestamps ParseTimestamp accepts
+// but that lack a time zone offset, or use a space instead of T
+var ErrMissingOffset = errors.New("timestamp must be RFC 3339 with a time zone offset such as Z or +02:00")
+
+// lenientFormats are the formats ParseTimestamp accepts
+var lenientFormats = []string{
+	time.RFC3339,                  // 2006-01-02T15:04:05Z07:00
+	time.RFC3339Nano,              // 2006-01-02T15:04:05.999999999Z07:00
+	"2006-01-02T15:04:05",         // Without timezone
+	"2006-01-02 15:04:05",         // Space separator
+	"2006-01-02T15:04:05.000Z",    // With milliseconds
+	"2006-01-02T15:04:05.000000Z", // With microseconds
+}
+
+// ParseStrictTimestamp parses a timestamp in RFC3339 with an explicit offset, as the
+// STRICT_TIME_PARSING setting requires
+func ParseStrictTimestamp(timestamp string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err == nil {
+		return t, nil
 	}
+	// Tell naive timestamps apart from ones that don't parse at all
+	if _, lenientErr := ParseTimestamp(timestamp); lenientErr == nil {
+		return time.Time{}, ErrMissingOffset
+	}
+	return time.Time{}, err
+}
 
+// ParseTimestamp parses a timestamp string in ISO 8601 format
+// Supports formats: RFC3339 (2006-01-02T15:04:05Z07:00) and similar variations
+func ParseTimestamp(timestamp string) (time.Time, error) {
 	var lastErr error
-	for _, format := range formats {
+	for _, format := range lenientFormats {
 		t, err := time.Parse(format, timestamp)
 		if err == nil {
 			return t, nil